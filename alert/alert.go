@@ -0,0 +1,302 @@
+// Package alert оценивает правила оповещения по результатам проверок ресурсов
+// и рассылает оповещения через Notifier.
+package alert
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// Типы правил оповещения
+const (
+	RuleDown         = "down"         // ресурс недоступен Probes проверок подряд
+	RuleLatency      = "latency"      // время отклика выше Threshold в течение For
+	RuleAvailability = "availability" // доступность за Window ниже Availability %
+	RuleCertificate  = "certificate"  // есть предупреждения о сертификате https ресурса
+)
+
+// FlappingRule - имя правила в оповещениях о начале и окончании нестабильности
+// ресурса. Пока ресурс нестабилен, оповещения по остальным правилам не отправляются.
+const FlappingRule = "flapping"
+
+// Rule - правило оповещения.
+type Rule struct {
+	Name string
+	Type string
+	URL  string // шаблон url в формате path.Match, пустой - все url
+
+	Probes       int           // для RuleDown
+	Threshold    time.Duration // для RuleLatency
+	For          time.Duration // для RuleLatency
+	Availability float64       // для RuleAvailability, %
+	Window       time.Duration // для RuleAvailability
+}
+
+// Validate проверяет настройки правила.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule without name")
+	}
+	if _, err := path.Match(r.URL, ""); err != nil {
+		return fmt.Errorf("rule %s: bad url pattern: %v", r.Name, err)
+	}
+	switch r.Type {
+	case RuleDown:
+		if r.Probes <= 0 {
+			return fmt.Errorf("rule %s: probes must be positive", r.Name)
+		}
+	case RuleLatency:
+		if r.Threshold <= 0 {
+			return fmt.Errorf("rule %s: threshold must be positive", r.Name)
+		}
+	case RuleAvailability:
+		if r.Availability <= 0 || r.Availability > 100 || r.Window <= 0 {
+			return fmt.Errorf("rule %s: availability must be in (0, 100] and window positive", r.Name)
+		}
+	case RuleCertificate:
+	default:
+		return fmt.Errorf("rule %s: unknown type %q", r.Name, r.Type)
+	}
+	return nil
+}
+
+func (r *Rule) matches(url string) bool {
+	if r.URL == "" {
+		return true
+	}
+	ok, _ := path.Match(r.URL, url)
+	return ok
+}
+
+// Alert - оповещение о срабатывании или разрешении правила для url.
+type Alert struct {
+	Rule    string    `json:"rule"`
+	URL     string    `json:"url"`
+	Firing  bool      `json:"firing"` // false - правило перестало выполняться
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+func (a *Alert) String() string {
+	state := "RESOLVED"
+	if a.Firing {
+		state = "FIRING"
+	}
+	return fmt.Sprintf("[%s] %s %s: %s", state, a.Rule, a.URL, a.Message)
+}
+
+// Engine оценивает правила по результатам проверок и рассылает оповещения
+// при смене состояния правила для url. Рассылка выполняется в отдельной
+// горутине, чтобы медленные получатели не задерживали опрос.
+type Engine struct {
+	rules     []Rule
+	notifiers []Notifier
+	db        storage.Storage // для RuleAvailability и отключений оповещений, может быть nil
+
+	mu   sync.Mutex // защищает только urls, состояние url защищено urlState.mu
+	urls map[string]*urlState
+
+	queue chan *Alert
+	done  chan struct{}
+}
+
+// urlState - состояние правил для url. Результаты проверок одного url
+// оцениваются по очереди, а разных url - параллельно, чтобы запросы к
+// хранилищу для одного ресурса не задерживали сохранение результатов остальных.
+type urlState struct {
+	mu    sync.Mutex
+	flap  ruleState // FlappingRule
+	rules map[string]*ruleState
+}
+
+type ruleState struct {
+	firing   bool
+	notified bool      // отправлено оповещение о срабатывании, только тогда оповещаем о разрешении
+	failures int       // RuleDown: число неудачных проверок подряд
+	since    time.Time // RuleLatency: начало превышения порога
+}
+
+// queueSize - размер очереди оповещений, ожидающих отправки.
+const queueSize = 100
+
+// NewEngine создает движок оповещений и запускает рассылку.
+func NewEngine(db storage.Storage, rules []Rule, notifiers ...Notifier) (*Engine, error) {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+		if rules[i].Type == RuleAvailability && db == nil {
+			return nil, fmt.Errorf("rule %s: storage is required", rules[i].Name)
+		}
+	}
+	e := &Engine{
+		rules:     rules,
+		notifiers: notifiers,
+		db:        db,
+		urls:      make(map[string]*urlState),
+		queue:     make(chan *Alert, queueSize),
+		done:      make(chan struct{}),
+	}
+	go e.dispatch()
+	return e, nil
+}
+
+// Close дожидается отправки оповещений из очереди и останавливает рассылку.
+func (e *Engine) Close() {
+	close(e.queue)
+	<-e.done
+}
+
+// Observe оценивает правила по результату проверки url.
+func (e *Engine) Observe(url string, res *storage.Result) {
+	now := res.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	u := e.urlState(url)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if res.Flapping != u.flap.firing {
+		e.transition(&u.flap, &Alert{Rule: FlappingRule, URL: url, Firing: res.Flapping,
+			Message: fmt.Sprintf("state change %.1f%%", res.StateChange), Time: now}, res)
+	}
+
+	for i := range e.rules {
+		r := &e.rules[i]
+		if !r.matches(url) {
+			continue
+		}
+		st, ok := u.rules[r.Name]
+		if !ok {
+			st = new(ruleState)
+			u.rules[r.Name] = st
+		}
+
+		firing, msg := e.evaluate(r, st, url, res, now)
+		// у нестабильного ресурса состояние правила не меняется, чтобы после
+		// стабилизации оповестить о расхождении с последним отправленным
+		if firing == st.firing || res.Flapping {
+			continue
+		}
+		e.transition(st, &Alert{Rule: r.Name, URL: url, Firing: firing, Message: msg, Time: now}, res)
+	}
+}
+
+// urlState возвращает состояние правил для url, создавая его при первом обращении.
+func (e *Engine) urlState(url string) *urlState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	u, ok := e.urls[url]
+	if !ok {
+		u = &urlState{rules: make(map[string]*ruleState)}
+		e.urls[url] = u
+	}
+	return u
+}
+
+// transition переводит правило в состояние a.Firing и оповещает об этом.
+// Оповещение о срабатывании не отправляется, если оповещения для url
+// отключены, а о разрешении - если не было отправлено о срабатывании.
+func (e *Engine) transition(st *ruleState, a *Alert, res *storage.Result) {
+	st.firing = a.Firing
+	if !a.Firing {
+		if st.notified {
+			st.notified = false
+			e.send(a)
+		} else {
+			log.Println("[INFO] alert: firing was not sent, skip", a)
+		}
+		return
+	}
+	if e.silenced(a.URL, res, a.Time) {
+		log.Println("[INFO] alert: silenced", a)
+		return
+	}
+	st.notified = true
+	e.send(a)
+}
+
+// evaluate возвращает, выполняется ли правило после учета результата проверки.
+func (e *Engine) evaluate(r *Rule, st *ruleState, url string, res *storage.Result, now time.Time) (bool, string) {
+	switch r.Type {
+	case RuleDown:
+		if res.Latency >= 0 {
+			st.failures = 0
+			return false, "available"
+		}
+		st.failures++
+		return st.failures >= r.Probes, fmt.Sprintf("down for %d probes: %s %s", st.failures, res.Reason, res.Error)
+
+	case RuleLatency:
+		if res.Latency < 0 {
+			return st.firing, "" // недоступность проверяется правилом RuleDown
+		}
+		if time.Duration(res.Latency) <= r.Threshold {
+			st.since = time.Time{}
+			return false, fmt.Sprintf("latency %v", time.Duration(res.Latency))
+		}
+		if st.since.IsZero() {
+			st.since = now
+		}
+		return now.Sub(st.since) >= r.For, fmt.Sprintf("latency %v above %v for %v",
+			time.Duration(res.Latency), r.Threshold, now.Sub(st.since))
+
+	case RuleAvailability:
+		a, err := e.db.GetAvailability(url, now.Add(-r.Window), now.Add(1))
+		if err != nil {
+			log.Println("[WARN] alert: storage error", err)
+			return st.firing, ""
+		}
+		return a.TimeUptime < r.Availability, fmt.Sprintf("availability %.3f%% for last %v, expected %.3f%%",
+			a.TimeUptime, r.Window, r.Availability)
+
+	case RuleCertificate:
+		c := res.Certificate
+		if c == nil {
+			return st.firing, "" // ресурс без tls или подключиться не удалось
+		}
+		if len(c.Warnings) == 0 {
+			return false, "certificate is valid until " + c.Expires().UTC().Format("2006-01-02")
+		}
+		return true, strings.Join(c.Warnings, "; ")
+	}
+	return false, ""
+}
+
+// silenced возвращает true, если оповещения для url отключены или идет обслуживание.
+func (e *Engine) silenced(url string, res *storage.Result, now time.Time) bool {
+	if res.Maintenance || e.db == nil {
+		return res.Maintenance
+	}
+	ok, err := e.db.IsSilenced(url, now)
+	if err != nil {
+		log.Println("[WARN] alert: storage error", err)
+	}
+	return ok
+}
+
+func (e *Engine) send(a *Alert) {
+	select {
+	case e.queue <- a:
+	default:
+		log.Println("[ERROR] alert: queue is full, drop", a)
+	}
+}
+
+func (e *Engine) dispatch() {
+	defer close(e.done)
+	for a := range e.queue {
+		for _, n := range e.notifiers {
+			if err := n.Notify(a); err != nil {
+				log.Printf("[ERROR] alert: %T failed: %v", n, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// recorder запоминает отправленные оповещения.
+type recorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recorder) Notify(a *Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, *a)
+	return nil
+}
+
+var start = time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+
+func result(i int, lat time.Duration) *storage.Result {
+	return &storage.Result{Time: start.Add(time.Duration(i) * time.Minute), Latency: int64(lat)}
+}
+
+func TestRuleDown(t *testing.T) {
+	rec := new(recorder)
+	e, err := NewEngine(nil, []Rule{{Name: "down", Type: RuleDown, Probes: 3}}, rec)
+	assert.Nil(t, err)
+
+	url := "https://ya.ru"
+	e.Observe(url, result(0, -1))
+	e.Observe(url, result(1, -1))
+	e.Observe(url, result(2, time.Millisecond))
+	e.Observe(url, result(3, -1))
+	e.Observe(url, result(4, -1))
+	e.Observe(url, result(5, -1))
+	e.Observe(url, result(6, -1)) // уже сработало, повторно не отправляется
+	e.Observe(url, result(7, time.Millisecond))
+	e.Close()
+
+	if assert.Len(t, rec.alerts, 2) {
+		assert.True(t, rec.alerts[0].Firing)
+		assert.Equal(t, url, rec.alerts[0].URL)
+		assert.Equal(t, start.Add(5*time.Minute), rec.alerts[0].Time)
+		assert.False(t, rec.alerts[1].Firing)
+		assert.Equal(t, start.Add(7*time.Minute), rec.alerts[1].Time)
+	}
+}
+
+func TestRuleLatency(t *testing.T) {
+	rec := new(recorder)
+	rule := Rule{Name: "slow", Type: RuleLatency, URL: "https://*.ru", Threshold: time.Second, For: 2 * time.Minute}
+	e, err := NewEngine(nil, []Rule{rule}, rec)
+	assert.Nil(t, err)
+
+	e.Observe("https://google.com", result(0, 5*time.Second)) // не подходит под шаблон
+	e.Observe("https://ya.ru", result(0, 2*time.Second))
+	e.Observe("https://ya.ru", result(1, 2*time.Second))
+	e.Observe("https://ya.ru", result(2, -1)) // недоступность не меняет состояние
+	e.Observe("https://ya.ru", result(3, 2*time.Second))
+	e.Observe("https://ya.ru", result(4, 100*time.Millisecond))
+	e.Close()
+
+	if assert.Len(t, rec.alerts, 2) {
+		assert.Equal(t, "https://ya.ru", rec.alerts[0].URL)
+		assert.True(t, rec.alerts[0].Firing)
+		assert.Equal(t, start.Add(3*time.Minute), rec.alerts[0].Time)
+		assert.False(t, rec.alerts[1].Firing)
+	}
+}
+
+func TestRuleAvailability(t *testing.T) {
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+
+	rec := new(recorder)
+	rule := Rule{Name: "sla", Type: RuleAvailability, Availability: 90, Window: 10 * time.Minute}
+	e, err := NewEngine(db, []Rule{rule}, rec)
+	assert.Nil(t, err)
+
+	url := "https://ya.ru"
+	observe := func(i int, lat time.Duration) {
+		r := result(i, lat)
+		assert.Nil(t, db.PutResult(url, r))
+		e.Observe(url, r)
+	}
+	for i := 0; i < 5; i++ {
+		observe(i, time.Millisecond)
+	}
+	observe(5, -1)
+	observe(6, -1)
+	e.Close()
+
+	if assert.Len(t, rec.alerts, 1) {
+		assert.True(t, rec.alerts[0].Firing)
+		assert.Equal(t, start.Add(6*time.Minute), rec.alerts[0].Time)
+	}
+}
+
+func TestRuleCertificate(t *testing.T) {
+	rec := new(recorder)
+	e, err := NewEngine(nil, []Rule{{Name: "cert", Type: RuleCertificate}}, rec)
+	assert.Nil(t, err)
+
+	url := "https://ya.ru"
+	observe := func(i int, warnings ...string) {
+		r := result(i, time.Millisecond)
+		r.Certificate = &storage.CertInfo{
+			Chain:    []storage.Certificate{{NotAfter: start.Add(30 * 24 * time.Hour)}},
+			Warnings: warnings,
+		}
+		e.Observe(url, r)
+	}
+	observe(0)
+	observe(1, "certificate expires in 5 days on 2020-01-06")
+	e.Observe(url, result(2, -1)) // без сертификата состояние не меняется
+	observe(3)
+	e.Close()
+
+	if assert.Len(t, rec.alerts, 2) {
+		assert.True(t, rec.alerts[0].Firing)
+		assert.Equal(t, "certificate expires in 5 days on 2020-01-06", rec.alerts[0].Message)
+		assert.False(t, rec.alerts[1].Firing)
+		assert.Equal(t, start.Add(3*time.Minute), rec.alerts[1].Time)
+	}
+}
+
+func TestSilence(t *testing.T) {
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+	err := db.PutSilence(&storage.Silence{URL: "https://ya.ru", Start: start, End: start.Add(5 * time.Minute)})
+	assert.Nil(t, err)
+
+	rec := new(recorder)
+	e, err := NewEngine(db, []Rule{{Name: "down", Type: RuleDown, Probes: 1}}, rec)
+	assert.Nil(t, err)
+
+	e.Observe("https://ya.ru", result(0, -1))
+	e.Observe("https://google.com", &storage.Result{Time: start, Latency: -1, Maintenance: true})
+	// о разрешении не оповещаем, т.к. о срабатывании не оповещали
+	e.Observe("https://ya.ru", result(6, time.Millisecond))
+	e.Observe("https://ya.ru", result(7, -1))
+	e.Observe("https://ya.ru", result(8, time.Millisecond))
+	e.Close()
+
+	var got []string
+	for _, a := range rec.alerts {
+		got = append(got, fmt.Sprintf("%s %v %d", a.URL, a.Firing, a.Time.Minute()))
+	}
+	assert.Equal(t, []string{"https://ya.ru true 7", "https://ya.ru false 8"}, got)
+}
+
+func TestObserveConcurrent(t *testing.T) {
+	// медленное хранилище для одного url не задерживает оценку остальных
+	db := &slowStorage{release: make(chan struct{})}
+	rec := new(recorder)
+	e, err := NewEngine(db, []Rule{{Name: "availability", Type: RuleAvailability, Availability: 99, Window: time.Hour}}, rec)
+	assert.Nil(t, err)
+
+	slowDone := make(chan struct{})
+	go func() {
+		e.Observe("https://slow.ru", result(0, -1))
+		close(slowDone)
+	}()
+	for db.calls() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		e.Observe("https://ya.ru", result(0, time.Millisecond))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("observe is blocked by another url")
+	}
+	close(db.release)
+	<-slowDone
+	e.Close()
+}
+
+// slowStorage отвечает на запросы доступности https://slow.ru только после
+// закрытия release.
+type slowStorage struct {
+	storage.Storage
+	release chan struct{}
+	mu      sync.Mutex
+	n       int
+}
+
+func (s *slowStorage) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.n
+}
+
+func (s *slowStorage) GetAvailability(url string, from, to time.Time) (*storage.Availability, error) {
+	s.mu.Lock()
+	s.n++
+	s.mu.Unlock()
+	if url == "https://slow.ru" {
+		<-s.release
+	}
+	return &storage.Availability{TimeUptime: 100}, nil
+}
+
+func (s *slowStorage) IsSilenced(url string, t time.Time) (bool, error) {
+	return false, nil
+}
+
+func TestFlapping(t *testing.T) {
+	rec := new(recorder)
+	e, err := NewEngine(nil, []Rule{{Name: "down", Type: RuleDown, Probes: 1}}, rec)
+	assert.Nil(t, err)
+
+	url := "https://ya.ru"
+	flap := func(i int, lat time.Duration, flapping bool) {
+		r := result(i, lat)
+		r.Flapping = flapping
+		e.Observe(url, r)
+	}
+	flap(0, -1, false)
+	flap(1, time.Millisecond, true) // переходы нестабильного ресурса не отправляются
+	flap(2, -1, true)
+	flap(3, time.Millisecond, true)
+	flap(4, -1, false) // после стабилизации состояние совпадает с отправленным
+	flap(5, time.Millisecond, false)
+	e.Close()
+
+	var got []string
+	for _, a := range rec.alerts {
+		got = append(got, fmt.Sprintf("%s %v %d", a.Rule, a.Firing, a.Time.Minute()))
+	}
+	assert.Equal(t, []string{
+		"down true 0",
+		"flapping true 1",
+		"flapping false 4",
+		"down false 5",
+	}, got)
+}
+
+func TestRuleValidate(t *testing.T) {
+	bad := []Rule{
+		{Type: RuleDown, Probes: 1},
+		{Name: "a", Type: "unknown"},
+		{Name: "a", Type: RuleDown},
+		{Name: "a", Type: RuleLatency},
+		{Name: "a", Type: RuleAvailability, Availability: 101, Window: time.Hour},
+		{Name: "a", Type: RuleDown, Probes: 1, URL: "["},
+	}
+	for _, r := range bad {
+		assert.NotNil(t, r.Validate(), "%+v", r)
+	}
+}
+
+func TestStdoutNotifier(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	n := &StdoutNotifier{W: buf}
+	err := n.Notify(&Alert{Rule: "down", URL: "https://ya.ru", Firing: true, Message: "timeout", Time: start})
+	assert.Nil(t, err)
+	assert.Equal(t, "2019-05-01T00:00:00Z [FIRING] down https://ya.ru: timeout\n", buf.String())
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	got := make(chan Alert, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&a))
+		got <- a
+	}))
+	defer ts.Close()
+
+	a := &Alert{Rule: "down", URL: "https://ya.ru", Firing: true, Message: "timeout", Time: start}
+	n := &WebhookNotifier{URL: ts.URL}
+	assert.Nil(t, n.Notify(a))
+	assert.Equal(t, *a, <-got)
+
+	n.URL = ts.URL + "/\x7f"
+	assert.NotNil(t, n.Notify(a))
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer lis.Close()
+
+	got := make(chan string, 1)
+	go serveSMTP(lis, got)
+
+	n := &SMTPNotifier{Addr: lis.Addr().String(), From: "mon@localhost", To: []string{"ops@localhost"}}
+	err = n.Notify(&Alert{Rule: "down", URL: "https://ya.ru", Firing: true, Message: "timeout\r\nBcc: evil@localhost", Time: start})
+	assert.Nil(t, err)
+
+	msg := <-got
+	assert.Contains(t, msg, "To: ops@localhost")
+	// перевод строки из текста оповещения не добавляет заголовок
+	assert.Contains(t, msg, "Subject: [FIRING] down https://ya.ru: timeout Bcc: evil@localhost\n")
+}
+
+// serveSMTP принимает одно письмо по минимальному подмножеству SMTP
+// и отправляет его текст в got.
+func serveSMTP(lis net.Listener, got chan<- string) {
+	conn, err := lis.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			body, err := ioutil.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			got <- string(body)
+			tp.PrintfLine("250 ok")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+}
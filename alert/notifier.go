@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Notifier отправляет оповещение получателю.
+type Notifier interface {
+	Notify(a *Alert) error
+}
+
+// StdoutNotifier печатает оповещения в W, по умолчанию в os.Stdout.
+type StdoutNotifier struct {
+	W io.Writer
+}
+
+func (n *StdoutNotifier) Notify(a *Alert) error {
+	w := n.W
+	if w == nil {
+		w = os.Stdout
+	}
+	_, err := fmt.Fprintf(w, "%s %s\n", a.Time.Format(time.RFC3339), a)
+	return err
+}
+
+// WebhookNotifier отправляет оповещение POST запросом с json телом на URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client // по умолчанию клиент с таймаутом 5 секунд
+}
+
+var defaultWebhookClient = &http.Client{Timeout: 5 * time.Second}
+
+func (n *WebhookNotifier) Notify(a *Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	client := n.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	resp, err := client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: unexpected status %s", n.URL, resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier отправляет оповещение письмом через SMTP сервер Addr (host:port).
+type SMTPNotifier struct {
+	Addr string
+	Auth smtp.Auth // может быть nil
+	From string
+	To   []string
+}
+
+func (n *SMTPNotifier) Notify(a *Alert) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", headerValue(n.From))
+	fmt.Fprintf(&msg, "To: %s\r\n", headerValue(strings.Join(n.To, ", ")))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerValue(a.String()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n", a.Message)
+	return smtp.SendMail(n.Addr, n.Auth, n.From, n.To, msg.Bytes())
+}
+
+// headerReplacer заменяет переводы строк пробелами.
+var headerReplacer = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// headerValue возвращает значение для заголовка письма. Переводы строк
+// убираются, т.к. текст оповещения может содержать данные ресурса (например,
+// TXT запись), которые иначе добавили бы в письмо свои заголовки.
+func headerValue(s string) string {
+	return headerReplacer.Replace(s)
+}
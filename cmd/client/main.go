@@ -38,10 +38,21 @@ func main() {
 	}
 
 	log.Println()
-	minLat, err := client.GetMinLatency(ctx, &pb.Empty{})
+	for _, url := range urls {
+		cert, err := client.GetCertificateInfo(ctx, &pb.RequestURL{Url: url})
+		log.Printf(url+": certificate: %+v error: %v", cert, err)
+	}
+
+	log.Println()
+	minLat, err := client.GetMinLatency(ctx, &pb.Window{})
 	log.Printf("GetMinLatency: response: %+v ms: %d error: %v", minLat, minLat.AvgLatency/1000000, err)
 
 	log.Println()
-	maxLat, err := client.GetMaxLatency(ctx, &pb.Empty{})
+	maxLat, err := client.GetMaxLatency(ctx, &pb.Window{})
 	log.Printf("GetMaxLatency: response: %+v ms: %d error: %v", maxLat, maxLat.AvgLatency/1000000, err)
+
+	log.Println()
+	lastHour := &pb.Window{Last: int64(time.Hour)}
+	maxLat, err = client.GetMaxLatency(ctx, lastHour)
+	log.Printf("GetMaxLatency for last hour: response: %+v ms: %d error: %v", maxLat, maxLat.AvgLatency/1000000, err)
 }
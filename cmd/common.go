@@ -2,29 +2,119 @@ package cmd
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"strings"
+
+	"github.com/akosourov/monitoring/poller"
 )
 
+// MakeURLs читает файл с сайтами и возвращает их https url.
 func MakeURLs(fname string) ([]string, error) {
+	targets, err := MakeTargets(fname)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, len(targets))
+	for i, t := range targets {
+		urls[i] = t.URL
+	}
+	return urls, nil
+}
+
+// MakeTargets читает файл с сайтами. Каждая строка файла имеет вид
+//
+//	url [опция=значение ...]
+//
+// где опции:
+//
+//	method=GET             - метод запроса (HEAD, GET, POST)
+//	body=...               - тело POST запроса
+//	status=200-299,301     - допустимые коды ответа
+//	contains=...           - тело ответа содержит подстроку
+//	regex=...              - тело ответа соответствует регулярному выражению
+//	json=path.to.field=... - значение поля json ответа
+//	send=...               - данные для отправки tcp ресурсу
+//	expect=...             - ответ tcp ресурса содержит подстроку
+//	record=A               - тип записей dns ресурса
+//	values=1.2.3.4,...     - значения, которые должны быть среди записей dns ресурса
+//
+// Url без схемы считается https, tcp ресурсы задаются в виде tcp://host:port,
+// dns - в виде dns://resolver[:port]/name, grpc - в виде grpc://host:port/service
+// или grpcs://host:port/service для подключения по tls.
+func MakeTargets(fname string) ([]poller.Target, error) {
 	f, err := os.Open(fname)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	urls := []string{}
+	targets := []poller.Target{}
 	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		url := strings.TrimSpace(scanner.Text())
-		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-			url = "https://" + url
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		t, err := parseTarget(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fname, n, err)
 		}
-		urls = append(urls, url)
+		targets = append(targets, t)
 	}
-	if scanner.Err() != nil {
+	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
 
-	return urls, nil
+	return targets, nil
+}
+
+func parseTarget(fields []string) (poller.Target, error) {
+	url := fields[0]
+	if !strings.Contains(url, "://") {
+		url = "https://" + url
+	}
+	t := poller.Target{URL: url}
+
+	for _, opt := range fields[1:] {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return t, fmt.Errorf("bad option %q", opt)
+		}
+		key, val := kv[0], kv[1]
+		switch key {
+		case "method":
+			t.Method = val
+		case "body":
+			t.Body = val
+		case "send":
+			t.Send = val
+		case "expect":
+			t.Expect = val
+		case "record":
+			t.Record = val
+		case "values":
+			t.Values = append(t.Values, strings.Split(val, ",")...)
+		case "status":
+			for _, s := range strings.Split(val, ",") {
+				r, err := poller.ParseStatusRange(s)
+				if err != nil {
+					return t, err
+				}
+				t.Status = append(t.Status, r)
+			}
+		case poller.CheckContains, poller.CheckRegex:
+			t.Checks = append(t.Checks, poller.Check{Type: key, Value: val})
+		case poller.CheckJSON:
+			pv := strings.SplitN(val, "=", 2)
+			if len(pv) != 2 {
+				return t, fmt.Errorf("bad json check %q", val)
+			}
+			t.Checks = append(t.Checks, poller.Check{Type: key, Path: pv[0], Value: pv[1]})
+		default:
+			return t, fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	return t, t.Validate()
 }
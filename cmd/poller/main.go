@@ -2,15 +2,16 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"flag"
 	"log"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"github.com/akosourov/monitoring/cmd"
+	"github.com/akosourov/monitoring/config"
 	"github.com/akosourov/monitoring/poller"
 	"github.com/akosourov/monitoring/storage"
 )
@@ -20,19 +21,19 @@ func main() {
 	interval := flag.String("interval", "10s", "Poll interval")
 	flag.Parse()
 
-	dur, err := time.ParseDuration(*interval)
+	dur, err := config.ParseInterval(*interval)
 	if err != nil {
 		log.Fatal("[ERROR] ", err)
 	}
 
-	urls, err := makeURLs(*sitesfname)
+	targets, err := cmd.MakeTargets(*sitesfname)
 	if err != nil {
 		log.Fatal("[ERROR] ", err)
 	}
 
 	db := storage.New("./bolt.db")
 	p := poller.Poller{
-		URLs:     urls,
+		Targets:  targets,
 		Interval: dur,
 		Timeout:  2 * time.Second,
 		DB:       db,
@@ -41,38 +42,17 @@ func main() {
 
 	// graceful shutdown
 	sigs := make(chan os.Signal, 1)
-	done := make(chan struct{})
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		sig := <-sigs
 		log.Println("[WARN] catch signal ", sig)
-		close(done)
-		p.Stop()
-		db.Close()
+		cancel()
 	}()
 
-	p.Start()
-}
-
-func makeURLs(fname string) ([]string, error) {
-	f, err := os.Open(fname)
+	err = p.Run(ctx)
+	db.Close()
 	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	urls := []string{}
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		url := strings.TrimSpace(scanner.Text())
-		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-			url = "https://" + url
-		}
-		urls = append(urls, url)
-	}
-	if scanner.Err() != nil {
-		return nil, err
+		log.Fatal("[ERROR] ", err)
 	}
-
-	return urls, nil
 }
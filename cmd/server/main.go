@@ -1,41 +1,125 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net"
 	"os"
 	"os/signal"
+	"reflect"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
 
+	"github.com/akosourov/monitoring/alert"
+	"github.com/akosourov/monitoring/cmd"
+	"github.com/akosourov/monitoring/config"
 	pb "github.com/akosourov/monitoring/grpc"
 	"github.com/akosourov/monitoring/poller"
 	"github.com/akosourov/monitoring/storage"
 )
 
+const defaultSLATarget = 99.9
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(validateConfig(os.Args[2:]))
+	}
+
+	configfname := flag.String("config", "", "YAML config file with server settings, targets and alerts, replaces -sites")
 	sitesfname := flag.String("sites", "./../sites.txt", "Filename with list of sites")
+	sitesReload := flag.Duration("sites-reload", 10*time.Second, "interval of checking sites file for changes, 0 - reload only on SIGHUP")
 	interval := flag.String("interval", "10s", "Poll interval")
 	address := flag.String("address", "localhost:8000", "host:port")
 	dbpath := flag.String("dbpath", "./../bolt.db", "path to db file")
 	workers := flag.Int("workers", 8, "pool of workers")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 5*time.Second, "how long to wait for probes in flight on shutdown")
+	retention := flag.Duration("retention", 0, "max age of stored latencies, 0 - keep forever")
+	retentionPoints := flag.Int("retention-points", 0, "max number of stored latencies per url, 0 - unlimited")
+	retention1m := flag.Duration("retention-1m", 7*24*time.Hour, "max age of minute rollups, 0 - keep forever")
+	retention1h := flag.Duration("retention-1h", 90*24*time.Hour, "max age of hour rollups, 0 - keep forever")
+	retention1d := flag.Duration("retention-1d", 0, "max age of day rollups, 0 - keep forever")
+	incidentOpen := flag.Int("incident-open", 3, "consecutive failed probes to open an incident")
+	incidentClose := flag.Int("incident-close", 2, "consecutive successful probes to close an incident")
+	slaTarget := flag.Float64("sla", defaultSLATarget, "target availability for SLA report, %")
+	compactInterval := flag.Duration("compact-interval", 0, "interval of db file compaction, 0 - never")
+	spread := flag.String("spread", poller.SpreadHash, "spreading of probes over the interval: hash or none")
+	jitter := flag.Duration("jitter", 0, "max random delay of each probe")
+	overrun := flag.String("overrun", poller.OverrunSkip, "what to do with a probe when the previous one is in flight: skip or queue")
+	flapHigh := flag.Float64("flap-high", 50, "percent of state change to consider a site flapping")
+	flapLow := flag.Float64("flap-low", 25, "percent of state change to consider a site stable again")
+	alertDown := flag.Int("alert-down", 3, "consecutive failed probes to send an alert, 0 - disabled")
+	alertLatency := flag.Duration("alert-latency", 0, "latency threshold to send an alert, 0 - disabled")
+	alertLatencyFor := flag.Duration("alert-latency-for", 5*time.Minute, "how long latency must stay above threshold")
+	alertAvailability := flag.Float64("alert-availability", 0, "availability threshold to send an alert, %, 0 - disabled")
+	alertWindow := flag.Duration("alert-window", time.Hour, "window of availability alert")
+	alertCert := flag.Bool("alert-cert", true, "send an alert when a certificate is invalid or expires soon")
+	certWarnDays := flag.Int("cert-warn-days", 14, "days before certificate expiry to warn, negative - never")
+	alertStdout := flag.Bool("alert-stdout", true, "print alerts to stdout")
+	alertWebhook := flag.String("alert-webhook", "", "url to POST alerts as json")
+	alertSMTP := flag.String("alert-smtp", "", "host:port of smtp server to send alerts")
+	alertFrom := flag.String("alert-from", "monitoring@localhost", "sender of alert emails")
+	alertTo := flag.String("alert-to", "", "comma separated recipients of alert emails")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [flags]\n       %s validate-config FILE...\n\nFlags:\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	// читаем файл с сайтами и конвертируем их в https url
-	urls, err := makeURLs(*sitesfname)
+	dur, err := config.ParseInterval(*interval)
 	if err != nil {
-		log.Fatalf("[ERROR] Cant open file: %v", err)
+		log.Fatal("[ERROR] ", err)
 	}
+	timeout := 2 * time.Second
+	rules := alertRules(*alertDown, *alertLatency, *alertLatencyFor, *alertAvailability, *alertWindow, *alertCert)
+	notifiers := alertNotifiers(*alertStdout, *alertWebhook, *alertSMTP, *alertFrom, *alertTo)
 
-	dur, err := time.ParseDuration(*interval)
+	// читаем файл с сайтами и настройками их проверки
+	watchfname, load := *sitesfname, cmd.MakeTargets
+	if *configfname != "" {
+		conf, err := config.Load(*configfname)
+		if err != nil {
+			log.Fatalf("[ERROR] Bad config:\n%v", err)
+		}
+		// явно заданные флаги имеют приоритет над файлом настроек
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if conf.Server.Address != "" && !set["address"] {
+			*address = conf.Server.Address
+		}
+		if conf.Server.DBPath != "" && !set["dbpath"] {
+			*dbpath = conf.Server.DBPath
+		}
+		if conf.Server.Workers > 0 && !set["workers"] {
+			*workers = conf.Server.Workers
+		}
+		if conf.Defaults.Interval > 0 && !set["interval"] {
+			dur = conf.Defaults.Interval
+		}
+		if conf.Server.Spread != "" && !set["spread"] {
+			*spread = conf.Server.Spread
+		}
+		if conf.Server.Jitter > 0 && !set["jitter"] {
+			*jitter = conf.Server.Jitter
+		}
+		if conf.Defaults.Timeout > 0 {
+			timeout = conf.Defaults.Timeout
+		}
+		rules = conf.Rules()
+		notifiers = configNotifiers(conf.Notifiers)
+		watchfname, load = *configfname, configTargets(conf)
+	}
+	targets, err := load(watchfname)
 	if err != nil {
-		log.Fatalf("[ERROR] Bad interval param: %v", err)
+		log.Fatalf("[ERROR] Cant open file: %v", err)
 	}
+	file := newFileTargets(targets)
 
 	// создаем подключение к бд
 	db := storage.NewBoltStorage(*dbpath)
@@ -49,18 +133,69 @@ func main() {
 		}
 	}()
 
+	// запускаем очистку устаревших данных
+	db.StartJanitor(storage.Retention{
+		MaxAge:    *retention,
+		MaxPoints: *retentionPoints,
+		RollupMaxAge: map[time.Duration]time.Duration{
+			storage.TierMinute: *retention1m,
+			storage.TierHour:   *retention1h,
+			storage.TierDay:    *retention1d,
+		},
+		Interval:        time.Hour,
+		CompactInterval: *compactInterval,
+	})
+
+	// добавляем ресурсы, сохраненные через api
+	targets, err = loadTargets(db, targets)
+	if err != nil {
+		log.Fatalf("[ERROR] Can't load targets: %v", err)
+	}
+
+	// настраиваем оповещения
+	alerts, err := alert.NewEngine(db, rules, notifiers...)
+	if err != nil {
+		log.Fatalf("[ERROR] Bad alert params: %v", err)
+	}
+
 	// Запускаем поллер
-	pol := poller.Poller{
-		URLs:     urls,
+	pol := &poller.Poller{
+		Targets:  targets,
 		Interval: dur,
-		Timeout:  2 * time.Second,
+		Timeout:  timeout,
 		DB:       db,
 		Workers:  *workers,
+
+		IncidentOpen:  *incidentOpen,
+		IncidentClose: *incidentClose,
+
+		Spread: *spread,
+		Jitter: *jitter,
+
+		Overrun: *overrun,
+
+		FlapHigh: *flapHigh,
+		FlapLow:  *flapLow,
+
+		Observers: []poller.Observer{alerts},
+
+		ShutdownTimeout: *shutdownTimeout,
+		CertWarnDays:    *certWarnDays,
 	}
+	if err := pol.Start(context.Background()); err != nil {
+		log.Fatalf("[ERROR] Can't start poller: %v", err)
+	}
+	polDone := make(chan error, 1)
 	go func() {
-		pol.Start()
+		polDone <- pol.Wait()
 	}()
 
+	// перечитываем файл с сайтами или настройками при изменении и по SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	stopWatch := make(chan struct{})
+	go newSitesWatcher(watchfname, load, db, pol, file).watch(*sitesReload, hup, stopWatch)
+
 	// Запускаем grpc сервер
 	lis, err := net.Listen("tcp", *address)
 	if err != nil {
@@ -68,41 +203,121 @@ func main() {
 	}
 
 	grpcServer := grpc.NewServer()
-	monServer := monitoringServer{db}
+	monServer := monitoringServer{db: db, poller: pol, file: file, slaTarget: *slaTarget}
 	pb.RegisterMonitoringServer(grpcServer, &monServer)
 
+	var shutdownOnce sync.Once
+	shutdown := func() {
+		shutdownOnce.Do(func() {
+			close(stopWatch)
+			pol.Stop()
+			grpcServer.GracefulStop()
+		})
+	}
+
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
-		sig := <-interrupt
-		log.Println("[WARN] signal", sig)
-		pol.Stop()
-		grpcServer.GracefulStop()
+		// сервер останавливается и при ошибке поллера, например при
+		// невозможности записи в бд
+		select {
+		case sig := <-interrupt:
+			log.Println("[WARN] signal", sig)
+		case err := <-polDone:
+			log.Println("[ERROR] poller stopped:", err)
+		}
+		shutdown()
 	}()
 
 	log.Println("[INFO] Start grpc server")
-	grpcServer.Serve(lis)
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Println("[ERROR] grpc server stopped:", err)
+	}
+	// оповещения закрываются только после остановки поллера, который их вызывает
+	shutdown()
+	alerts.Close()
 }
 
-func makeURLs(fname string) ([]string, error) {
-	f, err := os.Open(fname)
-	if err != nil {
-		return nil, err
+func alertRules(down int, latency, latencyFor time.Duration, availability float64, window time.Duration, cert bool) []alert.Rule {
+	var rules []alert.Rule
+	if down > 0 {
+		rules = append(rules, alert.Rule{Name: "down", Type: alert.RuleDown, Probes: down})
 	}
-	defer f.Close()
+	if latency > 0 {
+		rules = append(rules, alert.Rule{Name: "latency", Type: alert.RuleLatency, Threshold: latency, For: latencyFor})
+	}
+	if availability > 0 {
+		rules = append(rules, alert.Rule{Name: "availability", Type: alert.RuleAvailability,
+			Availability: availability, Window: window})
+	}
+	if cert {
+		rules = append(rules, alert.Rule{Name: "certificate", Type: alert.RuleCertificate})
+	}
+	return rules
+}
 
-	urls := []string{}
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		url := strings.TrimSpace(scanner.Text())
-		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-			url = "https://" + url
-		}
-		urls = append(urls, url)
+func alertNotifiers(stdout bool, webhook, smtp, from, to string) []alert.Notifier {
+	var notifiers []alert.Notifier
+	if stdout {
+		notifiers = append(notifiers, &alert.StdoutNotifier{})
+	}
+	if webhook != "" {
+		notifiers = append(notifiers, &alert.WebhookNotifier{URL: webhook})
 	}
-	if scanner.Err() != nil {
-		return nil, err
+	if smtp != "" && to != "" {
+		notifiers = append(notifiers, &alert.SMTPNotifier{Addr: smtp, From: from, To: strings.Split(to, ",")})
 	}
+	return notifiers
+}
 
-	return urls, nil
+func configNotifiers(n config.Notifiers) []alert.Notifier {
+	var notifiers []alert.Notifier
+	if n.Stdout {
+		notifiers = append(notifiers, &alert.StdoutNotifier{})
+	}
+	if n.Webhook != "" {
+		notifiers = append(notifiers, &alert.WebhookNotifier{URL: n.Webhook})
+	}
+	if n.SMTP != nil {
+		notifiers = append(notifiers, &alert.SMTPNotifier{Addr: n.SMTP.Addr, From: n.SMTP.From, To: n.SMTP.To})
+	}
+	return notifiers
+}
+
+// configTargets возвращает функцию чтения ресурсов из файла настроек.
+// Правила и получатели оповещений применяются только при запуске, поэтому
+// если при перечитывании они отличаются от conf, выводится предупреждение.
+func configTargets(conf *config.Config) func(string) ([]poller.Target, error) {
+	return func(fname string) ([]poller.Target, error) {
+		next, err := config.Load(fname)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(next.Rules(), conf.Rules()) || !reflect.DeepEqual(next.Notifiers, conf.Notifiers) {
+			log.Println("[WARN] Alerts or notifiers were changed in", fname, "- restart the server to apply them")
+		}
+		return next.PollerTargets(), nil
+	}
+}
+
+// validateConfig проверяет файлы настроек, переданные в args, и выводит
+// найденные ошибки. Возвращает код завершения программы.
+//
+//	server validate-config monitoring.yaml
+func validateConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: server validate-config FILE...")
+		return 2
+	}
+	code := 0
+	for _, fname := range args {
+		conf, err := config.Load(fname)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("%s: ok, %d targets, %d alert rules\n", fname, len(conf.Targets), len(conf.Rules()))
+	}
+	return code
 }
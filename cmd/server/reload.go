@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/akosourov/monitoring/poller"
+	"github.com/akosourov/monitoring/storage"
+)
+
+// sitesWatcher перечитывает файл с сайтами или файл настроек и применяет
+// изменения списка ресурсов к работающему поллеру.
+type sitesWatcher struct {
+	fname  string
+	load   func(fname string) ([]poller.Target, error) // чтение ресурсов из файла
+	db     storage.Storage
+	poller *poller.Poller
+	file   *fileTargets
+
+	modTime time.Time
+}
+
+func newSitesWatcher(fname string, load func(string) ([]poller.Target, error), db storage.Storage, p *poller.Poller,
+	file *fileTargets) *sitesWatcher {
+	w := &sitesWatcher{fname: fname, load: load, db: db, poller: p, file: file}
+	if fi, err := os.Stat(fname); err == nil {
+		w.modTime = fi.ModTime()
+	}
+	return w
+}
+
+// watch перечитывает файл при изменении времени модификации, которое проверяется
+// каждые interval (0 - не проверяется), и при получении значения из reload.
+// Работает до закрытия stop.
+func (w *sitesWatcher) watch(interval time.Duration, reload <-chan os.Signal, stop <-chan struct{}) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-tick:
+			fi, err := os.Stat(w.fname)
+			if err != nil {
+				log.Println("[WARN] Can't stat file", err)
+				continue
+			}
+			if fi.ModTime().Equal(w.modTime) {
+				continue
+			}
+			w.modTime = fi.ModTime()
+			log.Println("[INFO] File was changed:", w.fname)
+			w.reload()
+		case sig := <-reload:
+			log.Println("[INFO] signal", sig)
+			w.reload()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// reload читает файл и заменяет ресурсами из него список ресурсов поллера.
+// Ресурсы, сохраненные через api, не удаляются. При ошибке в файле
+// поллер продолжает работу с прежним списком.
+func (w *sitesWatcher) reload() {
+	w.file.mu.Lock()
+	defer w.file.mu.Unlock()
+	fromFile, err := w.load(w.fname)
+	if err != nil {
+		log.Println("[ERROR] Can't reload sites:", err)
+		return
+	}
+	targets, err := loadTargets(w.db, append([]poller.Target(nil), fromFile...))
+	if err != nil {
+		log.Println("[ERROR] Can't reload sites:", err)
+		return
+	}
+	diff, err := w.poller.SetTargets(targets)
+	if err != nil {
+		log.Println("[ERROR] Can't reload sites:", err)
+		return
+	}
+	w.file.set(fromFile)
+	if diff.Empty() {
+		log.Println("[INFO] Sites were reloaded without changes")
+		return
+	}
+	log.Println("[INFO] Sites were reloaded:", diff)
+}
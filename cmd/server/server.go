@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"log"
+	"time"
 
 	pb "github.com/akosourov/monitoring/grpc"
+	"github.com/akosourov/monitoring/poller"
 	"github.com/akosourov/monitoring/storage"
 )
 
 type monitoringServer struct {
-	db storage.Storage
+	db        storage.Storage
+	poller    *poller.Poller
+	file      *fileTargets
+	slaTarget float64 // целевая доступность по умолчанию, %
 }
 
 func (s *monitoringServer) GetURLInfo(ctx context.Context, req *pb.RequestURL) (*pb.ResponseInfo, error) {
@@ -19,8 +24,14 @@ func (s *monitoringServer) GetURLInfo(ctx context.Context, req *pb.RequestURL) (
 		return nil, err
 	}
 
-	avg, err := s.db.GetAvgLatency(req.Url)
-	if err != nil {
+	// у ресурса, который ни разу не был доступен, нет среднего времени отклика
+	var avg int64
+	if from, to, ok := windowRange(req.Window); ok {
+		avg, err = s.db.GetAvgLatencyRange(req.Url, from, to)
+	} else {
+		avg, err = s.db.GetAvgLatency(req.Url)
+	}
+	if err != nil && lat >= 0 {
 		log.Println("[WARN] storage error", err)
 		return nil, err
 	}
@@ -31,11 +42,32 @@ func (s *monitoringServer) GetURLInfo(ctx context.Context, req *pb.RequestURL) (
 	}
 	resp.AvgLatency = avg
 
+	// подробный результат есть только у проверок, сохраненных через PutResult
+	if res, err := s.db.GetLastResult(req.Url); err == nil {
+		resp.Status = int32(res.Status)
+		resp.FailedCheck = res.Check
+		resp.Reason = res.Reason
+		resp.Error = res.Error
+		resp.Flapping = res.Flapping
+		resp.StateChange = res.StateChange
+		resp.Attempts = int32(res.Attempts)
+		resp.Retried = res.Retried
+	}
+
 	return resp, nil
 }
 
-func (s *monitoringServer) GetMaxLatency(ctx context.Context, _ *pb.Empty) (*pb.ResponseLatency, error) {
-	url, lat, err := s.db.GetMaxLatency()
+func (s *monitoringServer) GetMaxLatency(ctx context.Context, req *pb.Window) (*pb.ResponseLatency, error) {
+	var (
+		url string
+		lat int64
+		err error
+	)
+	if from, to, ok := windowRange(req); ok {
+		url, lat, err = s.db.GetMaxLatencyRange(from, to)
+	} else {
+		url, lat, err = s.db.GetMaxLatency()
+	}
 	if err != nil {
 		log.Println("[WARN] storage error", err)
 		return nil, err
@@ -47,8 +79,17 @@ func (s *monitoringServer) GetMaxLatency(ctx context.Context, _ *pb.Empty) (*pb.
 	return resp, nil
 }
 
-func (s *monitoringServer) GetMinLatency(ctx context.Context, _ *pb.Empty) (*pb.ResponseLatency, error) {
-	url, lat, err := s.db.GetMinLatency()
+func (s *monitoringServer) GetMinLatency(ctx context.Context, req *pb.Window) (*pb.ResponseLatency, error) {
+	var (
+		url string
+		lat int64
+		err error
+	)
+	if from, to, ok := windowRange(req); ok {
+		url, lat, err = s.db.GetMinLatencyRange(from, to)
+	} else {
+		url, lat, err = s.db.GetMinLatency()
+	}
 	if err != nil {
 		log.Println("[WARN] storage error", err)
 		return nil, err
@@ -60,6 +101,369 @@ func (s *monitoringServer) GetMinLatency(ctx context.Context, _ *pb.Empty) (*pb.
 	return resp, nil
 }
 
+func (s *monitoringServer) GetLatencyBreakdown(ctx context.Context, req *pb.RequestURL) (*pb.ResponseBreakdown, error) {
+	res, err := s.db.GetLastResult(req.Url)
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+
+	resp := new(pb.ResponseBreakdown)
+	resp.Url = req.Url
+	resp.Timestamp = res.Time.UnixNano()
+	resp.Total = res.Latency
+	if res.Phases != nil {
+		resp.Dns = res.Phases.DNS
+		resp.Connect = res.Phases.Connect
+		resp.Tls = res.Phases.TLS
+		resp.Ttfb = res.Phases.TTFB
+		resp.Transfer = res.Phases.Transfer
+	}
+	return resp, nil
+}
+
+func (s *monitoringServer) GetLatencyPercentiles(ctx context.Context, req *pb.RequestPercentiles) (*pb.ResponsePercentiles, error) {
+	p, err := s.db.GetLatencyPercentiles(req.Url, time.Duration(req.Window))
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+
+	resp := new(pb.ResponsePercentiles)
+	resp.Url = req.Url
+	resp.Count = p.Count
+	resp.P50 = p.P50
+	resp.P90 = p.P90
+	resp.P95 = p.P95
+	resp.P99 = p.P99
+	return resp, nil
+}
+
+func (s *monitoringServer) GetAvailability(ctx context.Context, req *pb.RequestAvailability) (*pb.ResponseAvailability, error) {
+	from, to, ok := windowRange(req.Window)
+	if !ok {
+		from, to = time.Time{}, time.Now()
+	}
+	a, err := s.db.GetAvailability(req.Url, from, to)
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+	return availabilityResponse(req.Url, a), nil
+}
+
+func (s *monitoringServer) GetSLAReport(ctx context.Context, req *pb.RequestSLA) (*pb.ResponseSLA, error) {
+	from, to, ok := windowRange(req.Window)
+	if !ok {
+		from, to = time.Time{}, time.Now()
+	}
+	urls, err := s.db.GetURLs()
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+
+	resp := new(pb.ResponseSLA)
+	resp.Target = req.Target
+	if resp.Target == 0 {
+		resp.Target = s.slaTarget
+	}
+	for _, url := range urls {
+		a, err := s.db.GetAvailability(url, from, to)
+		if err != nil {
+			continue // нет данных за интервал
+		}
+		resp.Items = append(resp.Items, &pb.SLAItem{
+			Availability: availabilityResponse(url, a),
+			Met:          a.TimeUptime >= resp.Target,
+		})
+	}
+	return resp, nil
+}
+
+func (s *monitoringServer) ListIncidents(ctx context.Context, req *pb.RequestIncidents) (*pb.ResponseIncidents, error) {
+	from, to, ok := windowRange(req.Window)
+	if !ok {
+		from, to = time.Time{}, time.Now()
+	}
+	incidents, err := s.db.ListIncidents(req.Url, from, to)
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	resp := new(pb.ResponseIncidents)
+	for _, inc := range incidents {
+		item := &pb.Incident{
+			Url:      inc.URL,
+			Start:    inc.Start.UnixNano(),
+			Duration: int64(inc.Duration(now)),
+			Reason:   inc.Reason,
+			Error:    inc.Error,
+		}
+		if !inc.IsOpen() {
+			item.End = inc.End.UnixNano()
+		}
+		resp.Incidents = append(resp.Incidents, item)
+	}
+	return resp, nil
+}
+
+func (s *monitoringServer) GetCertificateInfo(ctx context.Context, req *pb.RequestURL) (*pb.ResponseCertificate, error) {
+	c, err := s.db.GetCertificate(req.Url)
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+
+	resp := &pb.ResponseCertificate{
+		Url:       req.Url,
+		Timestamp: unixNano(c.Time),
+		Error:     c.Error,
+		Warnings:  c.Warnings,
+		Expires:   unixNano(c.Expires()),
+	}
+	for _, cert := range c.Chain {
+		resp.Chain = append(resp.Chain, &pb.Certificate{
+			Subject:            cert.Subject,
+			Issuer:             cert.Issuer,
+			DnsNames:           cert.DNSNames,
+			NotBefore:          unixNano(cert.NotBefore),
+			NotAfter:           unixNano(cert.NotAfter),
+			SignatureAlgorithm: cert.SignatureAlgorithm,
+		})
+	}
+	return resp, nil
+}
+
+func availabilityResponse(url string, a *storage.Availability) *pb.ResponseAvailability {
+	return &pb.ResponseAvailability{
+		Url:        url,
+		Total:      a.Total,
+		Failures:   a.Failures,
+		Uptime:     a.Uptime,
+		Observed:   int64(a.Observed),
+		Downtime:   int64(a.Downtime),
+		TimeUptime: a.TimeUptime,
+		Excluded:   a.Excluded,
+	}
+}
+
+// windowRange возвращает интервал времени, заданный окном w.
+// Если окно не задано, то ok равен false.
+func windowRange(w *pb.Window) (from, to time.Time, ok bool) {
+	if w == nil || (w.Last == 0 && w.From == 0 && w.To == 0) {
+		return from, to, false
+	}
+	to = time.Now()
+	if w.Last > 0 {
+		return to.Add(-time.Duration(w.Last)), to, true
+	}
+	if w.To > 0 {
+		to = time.Unix(0, w.To)
+	}
+	return time.Unix(0, w.From), to, true
+}
+
+func (s *monitoringServer) AddSilence(ctx context.Context, req *pb.Silence) (*pb.Silence, error) {
+	silence := &storage.Silence{
+		URL:     req.Url,
+		Start:   unixTime(req.Start),
+		End:     unixTime(req.End),
+		Creator: req.Creator,
+		Comment: req.Comment,
+	}
+	if silence.Start.IsZero() {
+		silence.Start = time.Now()
+	}
+	if err := s.db.PutSilence(silence); err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+	log.Printf("[INFO] Add silence %d for %q by %s: %s", silence.ID, silence.URL, silence.Creator, silence.Comment)
+	return silenceResponse(silence), nil
+}
+
+func (s *monitoringServer) ListSilences(ctx context.Context, req *pb.Empty) (*pb.ResponseSilences, error) {
+	silences, err := s.db.ListSilences()
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+	resp := new(pb.ResponseSilences)
+	for i := range silences {
+		resp.Silences = append(resp.Silences, silenceResponse(&silences[i]))
+	}
+	return resp, nil
+}
+
+func (s *monitoringServer) DeleteSilence(ctx context.Context, req *pb.RequestID) (*pb.Empty, error) {
+	if err := s.db.DeleteSilence(req.Id); err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+	log.Printf("[INFO] Delete silence %d", req.Id)
+	return new(pb.Empty), nil
+}
+
+func (s *monitoringServer) AddMaintenance(ctx context.Context, req *pb.Maintenance) (*pb.Maintenance, error) {
+	m := &storage.Maintenance{
+		URL:      req.Url,
+		Start:    unixTime(req.Start),
+		Duration: time.Duration(req.Duration),
+		Every:    time.Duration(req.Every),
+		Until:    unixTime(req.Until),
+		Creator:  req.Creator,
+		Comment:  req.Comment,
+	}
+	if m.Start.IsZero() {
+		m.Start = time.Now()
+	}
+	if err := s.db.PutMaintenance(m); err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+	log.Printf("[INFO] Add maintenance %d for %q by %s: %s", m.ID, m.URL, m.Creator, m.Comment)
+	return maintenanceResponse(m), nil
+}
+
+func (s *monitoringServer) ListMaintenance(ctx context.Context, req *pb.Empty) (*pb.ResponseMaintenance, error) {
+	windows, err := s.db.ListMaintenance()
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+	resp := new(pb.ResponseMaintenance)
+	for i := range windows {
+		resp.Maintenance = append(resp.Maintenance, maintenanceResponse(&windows[i]))
+	}
+	return resp, nil
+}
+
+func (s *monitoringServer) DeleteMaintenance(ctx context.Context, req *pb.RequestID) (*pb.Empty, error) {
+	if err := s.db.DeleteMaintenance(req.Id); err != nil {
+		log.Println("[WARN] storage error", err)
+		return nil, err
+	}
+	log.Printf("[INFO] Delete maintenance %d", req.Id)
+	return new(pb.Empty), nil
+}
+
+func silenceResponse(s *storage.Silence) *pb.Silence {
+	return &pb.Silence{
+		Id:      s.ID,
+		Url:     s.URL,
+		Start:   unixNano(s.Start),
+		End:     unixNano(s.End),
+		Creator: s.Creator,
+		Comment: s.Comment,
+	}
+}
+
+func maintenanceResponse(m *storage.Maintenance) *pb.Maintenance {
+	return &pb.Maintenance{
+		Id:       m.ID,
+		Url:      m.URL,
+		Start:    unixNano(m.Start),
+		Duration: int64(m.Duration),
+		Every:    int64(m.Every),
+		Until:    unixNano(m.Until),
+		Creator:  m.Creator,
+		Comment:  m.Comment,
+	}
+}
+
+// unixTime возвращает время по timestamp (нс), для 0 - нулевое время.
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// unixNano возвращает timestamp (нс), для нулевого времени - 0.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// historyPageSize - число точек, читаемых из хранилища за один раз при отправке истории.
+const historyPageSize = 1000
+
+func (s *monitoringServer) GetHistory(req *pb.RequestHistory, stream pb.Monitoring_GetHistoryServer) error {
+	from := time.Unix(0, req.From)
+	to := time.Now()
+	if req.To > 0 {
+		to = time.Unix(0, req.To)
+	}
+
+	if req.Resolution >= int64(storage.TierMinute) {
+		return s.sendRollups(req, from, to, stream)
+	}
+
+	left := int(req.Limit)
+	for {
+		size := historyPageSize
+		if req.Limit > 0 && left < size {
+			size = left
+		}
+		points, err := s.db.GetLatencyRange(req.Url, from, to, size)
+		if err != nil {
+			log.Println("[WARN] storage error", err)
+			return err
+		}
+		for _, p := range points {
+			point := &pb.Point{Timestamp: p.Time.UnixNano(), Latency: p.Latency}
+			if p.Latency >= 0 {
+				point.Count, point.Min, point.Max = 1, p.Latency, p.Latency
+			} else {
+				point.Failures = 1
+			}
+			if err := stream.Send(point); err != nil {
+				return err
+			}
+		}
+
+		left -= len(points)
+		if len(points) < size || (req.Limit > 0 && left <= 0) {
+			return nil
+		}
+		from = points[len(points)-1].Time.Add(1)
+	}
+}
+
+// sendRollups отправляет агрегаты времени отклика с шагом req.Resolution.
+func (s *monitoringServer) sendRollups(req *pb.RequestHistory, from, to time.Time, stream pb.Monitoring_GetHistoryServer) error {
+	rollups, err := s.db.GetRollupRange(req.Url, from, to, time.Duration(req.Resolution))
+	if err != nil {
+		log.Println("[WARN] storage error", err)
+		return err
+	}
+	if req.Limit > 0 && len(rollups) > int(req.Limit) {
+		rollups = rollups[:req.Limit]
+	}
+	for _, r := range rollups {
+		point := &pb.Point{
+			Timestamp: r.Time.UnixNano(),
+			Latency:   r.Avg(),
+			Count:     r.Count,
+			Failures:  r.Failures,
+			Min:       r.Min,
+			Max:       r.Max,
+		}
+		if r.Count == 0 {
+			point.Latency = -1
+		}
+		if err := stream.Send(point); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *monitoringServer) putInitialData() error {
 	err := s.db.PutLatency("https://ya.ru", 200)
 	if err != nil {
@@ -78,6 +482,9 @@ func (s *monitoringServer) putInitialData() error {
 
 func newServer(dbpath string) *monitoringServer {
 	return &monitoringServer{
-		db: storage.NewBoltStorage(dbpath),
+		db:        storage.NewBoltStorage(dbpath),
+		poller:    new(poller.Poller),
+		file:      newFileTargets(nil),
+		slaTarget: defaultSLATarget,
 	}
 }
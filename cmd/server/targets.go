@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/akosourov/monitoring/grpc"
+	"github.com/akosourov/monitoring/poller"
+	"github.com/akosourov/monitoring/storage"
+)
+
+// loadTargets возвращает ресурсы из файла, дополненные ресурсами, добавленными
+// через api. Сохраненные в базе настройки заменяют настройки из файла с тем же url.
+func loadTargets(db storage.Storage, targets []poller.Target) ([]poller.Target, error) {
+	configs, err := db.ListTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(targets))
+	for i, t := range targets {
+		index[t.URL] = i
+	}
+	for _, config := range configs {
+		var t poller.Target
+		if err := json.Unmarshal(config, &t); err != nil {
+			log.Println("[ERROR] Skip stored target:", err)
+			continue
+		}
+		if i, ok := index[t.URL]; ok {
+			targets[i] = t
+			continue
+		}
+		index[t.URL] = len(targets)
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// fileTargets - url ресурсов из файла с сайтами или настроек. Изменения
+// ресурсов через api и перечитывание файла выполняются под mu, чтобы
+// перечитывание не потеряло ресурс, который добавлен в поллер, но еще
+// не сохранен в базе.
+type fileTargets struct {
+	mu   sync.Mutex
+	urls map[string]bool
+}
+
+func newFileTargets(targets []poller.Target) *fileTargets {
+	f := new(fileTargets)
+	f.set(targets)
+	return f
+}
+
+// set заменяет список ресурсов из файла, вызывается под mu.
+func (f *fileTargets) set(targets []poller.Target) {
+	f.urls = make(map[string]bool, len(targets))
+	for _, t := range targets {
+		f.urls[t.URL] = true
+	}
+}
+
+// AddTarget добавляет ресурс в работающий поллер и сохраняет его в базе.
+// Ресурсы, добавленные и измененные через api, сохраняются в базе. Ресурсы
+// из файла с сайтами удаляются только из файла.
+func (s *monitoringServer) AddTarget(ctx context.Context, req *pb.Target) (*pb.Target, error) {
+	t, err := targetFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	s.file.mu.Lock()
+	defer s.file.mu.Unlock()
+	if err := s.poller.AddTarget(t); err != nil {
+		return nil, err
+	}
+	if err := s.putTarget(t); err != nil {
+		s.poller.RemoveTarget(t.URL)
+		return nil, err
+	}
+	return s.GetTarget(ctx, &pb.RequestTarget{Url: t.URL})
+}
+
+func (s *monitoringServer) UpdateTarget(ctx context.Context, req *pb.Target) (*pb.Target, error) {
+	t, err := targetFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	s.file.mu.Lock()
+	defer s.file.mu.Unlock()
+	old, err := s.GetTarget(ctx, &pb.RequestTarget{Url: t.URL})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.poller.UpdateTarget(t); err != nil {
+		return nil, err
+	}
+	if err := s.putTarget(t); err != nil {
+		if prev, perr := targetFromRequest(old); perr == nil {
+			s.poller.UpdateTarget(prev)
+		}
+		return nil, err
+	}
+	return s.GetTarget(ctx, &pb.RequestTarget{Url: t.URL})
+}
+
+func (s *monitoringServer) RemoveTarget(ctx context.Context, req *pb.RequestTarget) (*pb.Empty, error) {
+	s.file.mu.Lock()
+	defer s.file.mu.Unlock()
+	// иначе ресурс вернется при следующем перечитывании файла
+	if s.file.urls[req.Url] {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is listed in the sites file, remove it there", req.Url)
+	}
+	if err := s.poller.RemoveTarget(req.Url); err != nil {
+		return nil, err
+	}
+	if err := s.db.DeleteTarget(req.Url); err != nil {
+		log.Println("[WARN] storage error", err)
+	}
+	return new(pb.Empty), nil
+}
+
+func (s *monitoringServer) ListTargets(ctx context.Context, req *pb.Empty) (*pb.ResponseTargets, error) {
+	resp := new(pb.ResponseTargets)
+	for _, t := range s.poller.ActiveTargets() {
+		resp.Targets = append(resp.Targets, targetResponse(&t))
+	}
+	return resp, nil
+}
+
+func (s *monitoringServer) GetTarget(ctx context.Context, req *pb.RequestTarget) (*pb.Target, error) {
+	for _, t := range s.poller.ActiveTargets() {
+		if t.URL == req.Url {
+			return targetResponse(&t), nil
+		}
+	}
+	return nil, errors.New(req.Url + " not exists")
+}
+
+func (s *monitoringServer) GetPollerStats(ctx context.Context, req *pb.Empty) (*pb.ResponsePollerStats, error) {
+	st := s.poller.Stats()
+	return &pb.ResponsePollerStats{
+		Scheduled: st.Scheduled,
+		Skipped:   st.Skipped,
+		Queued:    st.Queued,
+		Late:      st.Late,
+		InFlight:  int32(st.InFlight),
+	}, nil
+}
+
+func (s *monitoringServer) putTarget(t poller.Target) error {
+	config, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := s.db.PutTarget(t.URL, config); err != nil {
+		log.Println("[WARN] storage error", err)
+		return err
+	}
+	return nil
+}
+
+func targetFromRequest(req *pb.Target) (poller.Target, error) {
+	t := poller.Target{
+		URL:      req.Url,
+		Method:   req.Method,
+		Headers:  req.Headers,
+		Body:     req.Body,
+		Tags:     req.Tags,
+		Interval: time.Duration(req.Interval),
+		Timeout:  time.Duration(req.Timeout),
+		Send:     req.Send,
+		Expect:   req.Expect,
+		Record:   req.Record,
+		Values:   req.Values,
+	}
+	for _, s := range req.Status {
+		r, err := poller.ParseStatusRange(s)
+		if err != nil {
+			return t, err
+		}
+		t.Status = append(t.Status, r)
+	}
+	for _, c := range req.Checks {
+		t.Checks = append(t.Checks, poller.Check{Type: c.Type, Path: c.Path, Value: c.Value})
+	}
+	if r := req.Retry; r != nil {
+		t.Retry = &poller.Retry{Attempts: int(r.Attempts), Backoff: time.Duration(r.Backoff), On: r.On}
+	}
+	return t, nil
+}
+
+func targetResponse(t *poller.Target) *pb.Target {
+	resp := &pb.Target{
+		Url:      t.URL,
+		Method:   t.Method,
+		Headers:  t.Headers,
+		Body:     t.Body,
+		Tags:     t.Tags,
+		Interval: int64(t.Interval),
+		Timeout:  int64(t.Timeout),
+		Send:     t.Send,
+		Expect:   t.Expect,
+		Record:   t.Record,
+		Values:   t.Values,
+	}
+	for _, r := range t.Status {
+		resp.Status = append(resp.Status, r.String())
+	}
+	for _, c := range t.Checks {
+		resp.Checks = append(resp.Checks, &pb.Check{Type: c.Type, Path: c.Path, Value: c.Value})
+	}
+	if r := t.Retry; r != nil {
+		resp.Retry = &pb.Retry{Attempts: int32(r.Attempts), Backoff: int64(r.Backoff), On: r.On}
+	}
+	return resp
+}
@@ -0,0 +1,541 @@
+// Package config читает файл настроек сервера мониторинга в формате YAML:
+// настройки сервера, значения по умолчанию для ресурсов, правила оповещений,
+// получателей оповещений и список ресурсов с индивидуальными настройками.
+//
+// Пример:
+//
+//	server:
+//	  address: localhost:8000
+//	  dbpath: ./bolt.db
+//	  workers: 8
+//	defaults:
+//	  interval: 10s
+//	  timeout: 2s
+//	  headers: {User-Agent: monitoring}
+//	alerts:
+//	  - {name: down, type: down, probes: 3}
+//	notifiers:
+//	  stdout: true
+//	  webhook: http://localhost:9000/alerts
+//	targets:
+//	  - url: https://ya.ru
+//	  - url: tcp://localhost:6379
+//	    send: "PING\r\n"
+//	    expect: PONG
+//	  - url: dns://8.8.8.8/example.com
+//	    record: A
+//	    values: [93.184.216.34]
+//	  - url: grpcs://backend.example.com:443/orders
+//	  - url: https://example.com/api/health
+//	    interval: 30s
+//	    retry: {attempts: 3, backoff: 1s, on: [timeout, connect]}
+//	    method: GET
+//	    status: ["200-299"]
+//	    tags: [prod, api]
+//	    checks:
+//	      - {type: json, path: status, value: ok}
+//	    alerts:
+//	      - {name: slow, type: latency, threshold: 1s, for: 5m}
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/akosourov/monitoring/alert"
+	"github.com/akosourov/monitoring/poller"
+)
+
+// Config - содержимое файла настроек.
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Defaults  Target    `yaml:"defaults"` // значения по умолчанию для всех ресурсов
+	Alerts    []Rule    `yaml:"alerts"`   // правила оповещений для всех ресурсов
+	Notifiers Notifiers `yaml:"notifiers"`
+	Targets   []Target  `yaml:"targets"`
+}
+
+// Server - настройки сервера.
+type Server struct {
+	Address string `yaml:"address"`
+	DBPath  string `yaml:"dbpath"`
+	Workers int    `yaml:"workers"`
+
+	Spread string        `yaml:"spread"` // распределение опросов по периоду, см. poller.Poller
+	Jitter time.Duration `yaml:"jitter"`
+}
+
+// Target - настройки ресурса. Незаданные значения берутся из Config.Defaults,
+// при этом настройки http запроса и tcp обмена применяются только к ресурсам
+// своего вида, а record и values по умолчанию не задаются.
+type Target struct {
+	URL      string            `yaml:"url"`
+	Interval time.Duration     `yaml:"interval"`
+	Timeout  time.Duration     `yaml:"timeout"`
+	Method   string            `yaml:"method"`
+	Headers  map[string]string `yaml:"headers"` // дополняют заголовки по умолчанию
+	Body     string            `yaml:"body"`
+	Status   []string          `yaml:"status"` // диапазоны кодов ответа, например "200-299"
+	Checks   []Check           `yaml:"checks"`
+	Tags     []string          `yaml:"tags"`   // дополняют метки по умолчанию
+	Alerts   []Rule            `yaml:"alerts"` // правила оповещений только для этого ресурса
+	Retry    *Retry            `yaml:"retry"`
+	Send     string            `yaml:"send"`   // только для tcp://host:port
+	Expect   string            `yaml:"expect"` // только для tcp://host:port
+	Record   string            `yaml:"record"` // только для dns://resolver/name
+	Values   []string          `yaml:"values"` // только для dns://resolver/name
+
+	line        int
+	hasInterval bool // interval задан явно, в том числе нулевым
+}
+
+// Retry - повтор неудачной проверки, см. poller.Retry.
+type Retry struct {
+	Attempts int           `yaml:"attempts"`
+	Backoff  time.Duration `yaml:"backoff"`
+	On       []string      `yaml:"on"` // причины недоступности, например timeout
+}
+
+// Check - проверка тела ответа, см. poller.Check.
+type Check struct {
+	Type  string `yaml:"type"`
+	Path  string `yaml:"path"`
+	Value string `yaml:"value"`
+
+	line int
+}
+
+// Rule - правило оповещения, см. alert.Rule.
+type Rule struct {
+	Name         string        `yaml:"name"`
+	Type         string        `yaml:"type"`
+	URL          string        `yaml:"url"`
+	Probes       int           `yaml:"probes"`
+	Threshold    time.Duration `yaml:"threshold"`
+	For          time.Duration `yaml:"for"`
+	Availability float64       `yaml:"availability"`
+	Window       time.Duration `yaml:"window"`
+
+	line int
+}
+
+// Notifiers - получатели оповещений.
+type Notifiers struct {
+	Stdout  bool   `yaml:"stdout"`
+	Webhook string `yaml:"webhook"`
+	SMTP    *SMTP  `yaml:"smtp"`
+}
+
+// SMTP - настройки отправки оповещений по почте.
+type SMTP struct {
+	Addr string   `yaml:"addr"`
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+}
+
+// Error - ошибка в файле настроек.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.File + ": " + e.Msg
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Errors - все ошибки, найденные в файле настроек.
+type Errors []*Error
+
+func (es Errors) Error() string {
+	lines := make([]string, len(es))
+	for i, e := range es {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Load читает и проверяет файл настроек. Ошибки в содержимом файла
+// возвращаются в виде Errors с номерами строк.
+func Load(fname string) (*Config, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(fname, data)
+}
+
+// yamlLine - формат сообщений об ошибках yaml.v3 с номером строки.
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// Parse разбирает и проверяет настройки из data, fname используется в ошибках.
+func Parse(fname string, data []byte) (*Config, error) {
+	c := new(Config)
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && err != io.EOF {
+		var msgs []string
+		if te, ok := err.(*yaml.TypeError); ok {
+			msgs = te.Errors
+		} else {
+			msgs = []string{err.Error()}
+		}
+		var errs Errors
+		for _, msg := range msgs {
+			e := &Error{File: fname, Msg: msg}
+			if m := yamlLine.FindStringSubmatch(msg); m != nil {
+				e.Line, _ = strconv.Atoi(m[1])
+				e.Msg = m[2]
+			}
+			errs = append(errs, e)
+		}
+		return nil, errs
+	}
+
+	if errs := c.validate(fname); len(errs) > 0 {
+		return nil, errs
+	}
+	return c, nil
+}
+
+func (c *Config) validate(fname string) Errors {
+	var errs Errors
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, &Error{File: fname, Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Server.Workers < 0 {
+		fail(0, "server: workers must not be negative")
+	}
+	if s := c.Server.Spread; s != "" && s != poller.SpreadHash && s != poller.SpreadNone {
+		fail(0, "server: unknown spread %q", s)
+	}
+	if c.Server.Jitter < 0 {
+		fail(0, "server: jitter must not be negative")
+	}
+	if c.Defaults.URL != "" || len(c.Defaults.Alerts) > 0 {
+		fail(c.Defaults.line, "defaults: url and alerts are not allowed")
+	}
+	if c.Defaults.hasInterval && c.Defaults.Interval <= 0 {
+		fail(c.Defaults.line, "defaults: interval must be positive")
+	}
+	if c.Defaults.Timeout < 0 {
+		fail(c.Defaults.line, "defaults: negative timeout")
+	}
+	if r := c.Defaults.Retry; r != nil {
+		if err := (&poller.Retry{Attempts: r.Attempts, Backoff: r.Backoff, On: r.On}).Validate(); err != nil {
+			fail(c.Defaults.line, "defaults: %v", err)
+		}
+	}
+	defaultChecksOK := validateChecks("defaults", c.Defaults.Checks, fail)
+	if len(c.Targets) == 0 {
+		fail(0, "no targets")
+	}
+	if c.Notifiers.SMTP != nil && (c.Notifiers.SMTP.Addr == "" || len(c.Notifiers.SMTP.To) == 0) {
+		fail(0, "notifiers: smtp requires addr and to")
+	}
+
+	for _, r := range c.Alerts {
+		if err := r.validate(); err != nil {
+			fail(r.line, "%v", err)
+		}
+	}
+
+	seen := make(map[string]int)
+	for _, t := range c.Targets {
+		for _, r := range t.Alerts {
+			if r.URL != "" {
+				fail(r.line, "rule %s: url is not allowed in target rule", r.Name)
+				continue
+			}
+			if err := r.validate(); err != nil {
+				fail(r.line, "%v", err)
+			}
+		}
+
+		if t.URL == "" {
+			fail(t.line, "target without url")
+			continue
+		}
+		// отрицательный период отклоняется при проверке poller.Target
+		if t.hasInterval && t.Interval == 0 {
+			fail(t.line, "%s: interval must be positive", t.URL)
+		}
+		if line, ok := seen[t.URL]; ok {
+			fail(t.line, "duplicate target %s, see line %d", t.URL, line)
+			continue
+		}
+		seen[t.URL] = t.line
+
+		pt, err := c.merge(&t)
+		if err == nil {
+			err = pt.Validate()
+		}
+		checksOK := true
+		if len(t.Checks) > 0 {
+			checksOK = validateChecks(t.URL, t.Checks, fail)
+		} else if pt.Kind() == poller.KindHTTP {
+			checksOK = defaultChecksOK
+		}
+		// ошибки в проверках уже указаны со строками самих проверок
+		if err != nil && checksOK {
+			fail(t.line, "%v", err)
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+	return errs
+}
+
+// validateChecks проверяет настройки проверок тела ответа, чтобы указать
+// в ошибке строку проверки, а не ресурса. Возвращает false при ошибках.
+func validateChecks(name string, checks []Check, fail func(line int, format string, args ...interface{})) bool {
+	ok := true
+	for _, ch := range checks {
+		pc := poller.Check{Type: ch.Type, Path: ch.Path, Value: ch.Value}
+		if err := pc.Validate(); err != nil {
+			fail(ch.line, "%s: %v", name, err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// ParseInterval разбирает период опроса, заданный флагом командной строки.
+// Период должен быть положительным, как и в файле настроек.
+func ParseInterval(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad interval %s: %v", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("bad interval %s: interval must be positive", s)
+	}
+	return d, nil
+}
+
+// PollerTargets возвращает ресурсы для опроса с учетом значений по умолчанию.
+func (c *Config) PollerTargets() []poller.Target {
+	targets := make([]poller.Target, 0, len(c.Targets))
+	for i := range c.Targets {
+		t, _ := c.merge(&c.Targets[i]) // проверено при загрузке
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// Rules возвращает общие правила оповещений и правила отдельных ресурсов.
+func (c *Config) Rules() []alert.Rule {
+	rules := make([]alert.Rule, 0, len(c.Alerts))
+	for _, r := range c.Alerts {
+		rules = append(rules, r.rule())
+	}
+	for _, t := range c.Targets {
+		for _, r := range t.Alerts {
+			ar := r.rule()
+			ar.URL = escapePattern(t.URL)
+			rules = append(rules, ar)
+		}
+	}
+	return rules
+}
+
+// merge возвращает настройки ресурса t, дополненные значениями по умолчанию.
+func (c *Config) merge(t *Target) (poller.Target, error) {
+	d := &c.Defaults
+	pt := poller.Target{
+		URL:      t.URL,
+		Interval: t.Interval,
+		Timeout:  t.Timeout,
+		Send:     t.Send,
+		Expect:   t.Expect,
+		Record:   t.Record,
+		Values:   t.Values,
+	}
+	if pt.Interval == 0 {
+		pt.Interval = d.Interval
+	}
+	if pt.Timeout == 0 {
+		pt.Timeout = d.Timeout
+	}
+	pt.Tags = append(append(pt.Tags, d.Tags...), t.Tags...)
+
+	retry := t.Retry
+	if retry == nil {
+		retry = d.Retry
+	}
+	if retry != nil {
+		pt.Retry = &poller.Retry{Attempts: retry.Attempts, Backoff: retry.Backoff, On: retry.On}
+	}
+
+	if pt.Kind() != poller.KindHTTP {
+		// настройки самого ресурса проверяются в poller.Target.Validate
+		pt.Method, pt.Body, pt.Headers = t.Method, t.Body, t.Headers
+		for _, ch := range t.Checks {
+			pt.Checks = append(pt.Checks, poller.Check{Type: ch.Type, Path: ch.Path, Value: ch.Value})
+		}
+		if pt.Kind() == poller.KindTCP {
+			pt.Send = first(t.Send, d.Send)
+			pt.Expect = first(t.Expect, d.Expect)
+		}
+		return pt, parseStatus(&pt, t.Status)
+	}
+	pt.Method = first(t.Method, d.Method)
+	pt.Body = first(t.Body, d.Body)
+
+	if len(d.Headers)+len(t.Headers) > 0 {
+		pt.Headers = make(map[string]string)
+		for k, v := range d.Headers {
+			pt.Headers[k] = v
+		}
+		for k, v := range t.Headers {
+			pt.Headers[k] = v
+		}
+	}
+
+	status := t.Status
+	if len(status) == 0 {
+		status = d.Status
+	}
+	if err := parseStatus(&pt, status); err != nil {
+		return pt, err
+	}
+
+	checks := t.Checks
+	if len(checks) == 0 {
+		checks = d.Checks
+	}
+	for _, ch := range checks {
+		pt.Checks = append(pt.Checks, poller.Check{Type: ch.Type, Path: ch.Path, Value: ch.Value})
+	}
+	return pt, nil
+}
+
+func parseStatus(pt *poller.Target, status []string) error {
+	for _, s := range status {
+		r, err := poller.ParseStatusRange(s)
+		if err != nil {
+			return fmt.Errorf("%s: %v", pt.URL, err)
+		}
+		pt.Status = append(pt.Status, r)
+	}
+	return nil
+}
+
+func (r *Rule) rule() alert.Rule {
+	return alert.Rule{
+		Name:         r.Name,
+		Type:         r.Type,
+		URL:          r.URL,
+		Probes:       r.Probes,
+		Threshold:    r.Threshold,
+		For:          r.For,
+		Availability: r.Availability,
+		Window:       r.Window,
+	}
+}
+
+func (r *Rule) validate() error {
+	ar := r.rule()
+	return ar.Validate()
+}
+
+func first(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// escapePattern экранирует специальные символы path.Match, чтобы шаблон
+// совпадал только с самим url.
+func escapePattern(url string) string {
+	var b strings.Builder
+	for _, r := range url {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// UnmarshalYAML запоминает номер строки ресурса и проверяет, что в нем нет
+// неизвестных полей: вложенное декодирование не учитывает KnownFields.
+func (t *Target) UnmarshalYAML(node *yaml.Node) error {
+	type plain Target
+	if err := decodeStrict(node, (*plain)(t), "target"); err != nil {
+		return err
+	}
+	t.line = node.Line
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "interval" {
+			t.hasInterval = true
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML запоминает номер строки проверки и проверяет, что в ней нет неизвестных полей.
+func (c *Check) UnmarshalYAML(node *yaml.Node) error {
+	type plain Check
+	if err := decodeStrict(node, (*plain)(c), "check"); err != nil {
+		return err
+	}
+	c.line = node.Line
+	return nil
+}
+
+// UnmarshalYAML проверяет, что в настройках повтора нет неизвестных полей.
+func (r *Retry) UnmarshalYAML(node *yaml.Node) error {
+	type plain Retry
+	return decodeStrict(node, (*plain)(r), "retry")
+}
+
+// UnmarshalYAML запоминает номер строки правила и проверяет, что в нем нет неизвестных полей.
+func (r *Rule) UnmarshalYAML(node *yaml.Node) error {
+	type plain Rule
+	if err := decodeStrict(node, (*plain)(r), "rule"); err != nil {
+		return err
+	}
+	r.line = node.Line
+	return nil
+}
+
+// decodeStrict декодирует node в структуру v, возвращая ошибку для полей,
+// отсутствующих в тегах yaml структуры. name - название структуры в ошибке.
+func decodeStrict(node *yaml.Node, v interface{}, name string) error {
+	if node.Kind == yaml.MappingNode {
+		known := make(map[string]bool)
+		rt := reflect.TypeOf(v).Elem()
+		for i := 0; i < rt.NumField(); i++ {
+			if tag := rt.Field(i).Tag.Get("yaml"); tag != "" {
+				known[strings.Split(tag, ",")[0]] = true
+			}
+		}
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if !known[key.Value] {
+				return &yaml.TypeError{Errors: []string{
+					fmt.Sprintf("line %d: field %s not found in type %s", key.Line, key.Value, name),
+				}}
+			}
+		}
+	}
+	return node.Decode(v)
+}
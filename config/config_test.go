@@ -0,0 +1,191 @@
+package config
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akosourov/monitoring/alert"
+	"github.com/akosourov/monitoring/poller"
+)
+
+const validConfig = `
+server:
+  address: localhost:9000
+  workers: 4
+defaults:
+  interval: 10s
+  timeout: 2s
+  headers: {User-Agent: monitoring}
+  tags: [web]
+alerts:
+  - {name: down, type: down, probes: 3}
+notifiers:
+  stdout: true
+targets:
+  - url: https://ya.ru
+  - url: tcp://localhost:6379
+    send: "PING\r\n"
+    expect: PONG
+  - url: dns://127.0.0.1/example.com
+    record: AAAA
+    values: ["::1"]
+  - url: https://example.com/api?x=1
+    interval: 30s
+    retry: {attempts: 3, backoff: 1s}
+    method: GET
+    headers: {Accept: application/json}
+    status: ["200-299", "304"]
+    tags: [api]
+    checks:
+      - {type: json, path: status, value: ok}
+    alerts:
+      - {name: slow, type: latency, threshold: 1s, for: 5m}
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse("test.yaml", []byte(validConfig))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "localhost:9000", c.Server.Address)
+	assert.Equal(t, 4, c.Server.Workers)
+
+	targets := c.PollerTargets()
+	if assert.Len(t, targets, 4) {
+		assert.Equal(t, poller.Target{
+			URL:      "https://ya.ru",
+			Headers:  map[string]string{"User-Agent": "monitoring"},
+			Tags:     []string{"web"},
+			Interval: 10 * time.Second,
+			Timeout:  2 * time.Second,
+		}, targets[0])
+
+		// настройки http запроса по умолчанию не применяются к tcp ресурсам
+		assert.Equal(t, poller.Target{
+			URL:      "tcp://localhost:6379",
+			Tags:     []string{"web"},
+			Interval: 10 * time.Second,
+			Timeout:  2 * time.Second,
+			Send:     "PING\r\n",
+			Expect:   "PONG",
+		}, targets[1])
+		assert.Nil(t, targets[1].Validate())
+		assert.Equal(t, "AAAA", targets[2].Record)
+		assert.Equal(t, []string{"::1"}, targets[2].Values)
+		assert.Nil(t, targets[2].Validate())
+
+		api := targets[3]
+		assert.Equal(t, http.MethodGet, api.Method)
+		assert.Equal(t, 30*time.Second, api.Interval)
+		assert.Equal(t, 2*time.Second, api.Timeout)
+		assert.Equal(t, map[string]string{"User-Agent": "monitoring", "Accept": "application/json"}, api.Headers)
+		assert.Equal(t, []string{"web", "api"}, api.Tags)
+		assert.Equal(t, []poller.StatusRange{{Min: 200, Max: 299}, {Min: 304, Max: 304}}, api.Status)
+		assert.Equal(t, []poller.Check{{Type: poller.CheckJSON, Path: "status", Value: "ok"}}, api.Checks)
+		assert.Equal(t, &poller.Retry{Attempts: 3, Backoff: time.Second}, api.Retry)
+	}
+
+	rules := c.Rules()
+	if assert.Len(t, rules, 2) {
+		assert.Equal(t, alert.Rule{Name: "down", Type: alert.RuleDown, Probes: 3}, rules[0])
+		assert.Equal(t, `https://example.com/api\?x=1`, rules[1].URL)
+		assert.Equal(t, time.Second, rules[1].Threshold)
+	}
+
+	// шаблон правила ресурса совпадает только с его url
+	e, err := alert.NewEngine(nil, rules[1:])
+	assert.Nil(t, err)
+	e.Close()
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errs   []string
+	}{
+		{"syntax", "targets:\n  - url: [", []string{"test.yaml:2: did not find expected node content"}},
+		{"unknown field", "targets:\n  - url: https://ya.ru\n    methd: GET\n  - url: https://google.com\n    foo: 1\n", []string{
+			"test.yaml:3: field methd not found in type target",
+			"test.yaml:5: field foo not found in type target",
+		}},
+		{"top level field", "target:\n  - url: https://ya.ru\n", []string{
+			"test.yaml:1: field target not found in type config.Config",
+		}},
+		{"bad duration", "targets:\n  - url: https://ya.ru\n    timeout: soon\n", []string{
+			"test.yaml:3: cannot unmarshal !!str `soon` into time.Duration",
+		}},
+		{"zero interval", "defaults:\n  interval: 0s\ntargets:\n  - url: https://ya.ru\n    interval: 0s\n  - url: https://google.com\n    interval: -1s\n", []string{
+			"test.yaml:2: defaults: interval must be positive",
+			"test.yaml:4: https://ya.ru: interval must be positive",
+			"test.yaml:6: https://google.com: negative interval or timeout",
+		}},
+		{"retry", "defaults:\n  retry: {attempts: 0}\ntargets:\n  - url: https://ya.ru\n    retry: {attempts: 100}\n", []string{
+			"test.yaml:2: defaults: retry attempts must be in [1, 10]",
+			"test.yaml:4: https://ya.ru: retry attempts must be in [1, 10]",
+		}},
+		{"checks", `
+defaults:
+  checks:
+    - {type: regex, value: "("}
+targets:
+  - url: https://ya.ru
+    method: GET
+  - url: https://google.com
+    method: GET
+    checks:
+      - {type: contains, value: ok}
+      - {type: json}
+`, []string{
+			"test.yaml:4: defaults: error parsing regexp: missing closing ): `(`",
+			"test.yaml:12: https://google.com: empty json path",
+		}},
+		{"validation", `
+alerts:
+  - {name: down, type: down}
+targets:
+  - url: ftp://ya.ru
+  - url: https://ya.ru
+    status: ["ok"]
+  - url: https://google.com
+  - url: https://google.com
+    alerts:
+      - {name: slow, type: latency, threshold: 1s, url: "*"}
+`, []string{
+			"test.yaml:3: rule down: probes must be positive",
+			"test.yaml:5: ftp://ya.ru: unsupported url scheme",
+			"test.yaml:6: https://ya.ru: bad status range ok",
+			"test.yaml:9: duplicate target https://google.com, see line 8",
+			"test.yaml:11: rule slow: url is not allowed in target rule",
+		}},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse("test.yaml", []byte(tt.config))
+		errs, ok := err.(Errors)
+		if !assert.True(t, ok, "%s: %v", tt.name, err) {
+			continue
+		}
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		assert.Equal(t, tt.errs, got, tt.name)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	d, err := ParseInterval("30s")
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, d)
+
+	for _, s := range []string{"0s", "-1s", "soon"} {
+		_, err := ParseInterval(s)
+		assert.Error(t, err, s)
+	}
+	_, err = ParseInterval("0s")
+	assert.EqualError(t, err, "bad interval 0s: interval must be positive")
+}
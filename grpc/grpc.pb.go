@@ -53,8 +53,66 @@ func (m *Empty) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
+// Окно для расчета средних: последние last нс или интервал [from, to) (нс).
+// Если ничего не задано, то используются данные за все время.
+type Window struct {
+	Last                 int64    `protobuf:"varint,1,opt,name=last,proto3" json:"last,omitempty"`
+	From                 int64    `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To                   int64    `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Window) Reset()         { *m = Window{} }
+func (m *Window) String() string { return proto.CompactTextString(m) }
+func (*Window) ProtoMessage()    {}
+func (*Window) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{1}
+}
+
+func (m *Window) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Window.Unmarshal(m, b)
+}
+func (m *Window) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Window.Marshal(b, m, deterministic)
+}
+func (m *Window) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Window.Merge(m, src)
+}
+func (m *Window) XXX_Size() int {
+	return xxx_messageInfo_Window.Size(m)
+}
+func (m *Window) XXX_DiscardUnknown() {
+	xxx_messageInfo_Window.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Window proto.InternalMessageInfo
+
+func (m *Window) GetLast() int64 {
+	if m != nil {
+		return m.Last
+	}
+	return 0
+}
+
+func (m *Window) GetFrom() int64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *Window) GetTo() int64 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
 type RequestURL struct {
 	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Window               *Window  `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -64,7 +122,7 @@ func (m *RequestURL) Reset()         { *m = RequestURL{} }
 func (m *RequestURL) String() string { return proto.CompactTextString(m) }
 func (*RequestURL) ProtoMessage()    {}
 func (*RequestURL) Descriptor() ([]byte, []int) {
-	return fileDescriptor_bedfbfc9b54e5600, []int{1}
+	return fileDescriptor_bedfbfc9b54e5600, []int{2}
 }
 
 func (m *RequestURL) XXX_Unmarshal(b []byte) error {
@@ -92,9 +150,24 @@ func (m *RequestURL) GetUrl() string {
 	return ""
 }
 
+func (m *RequestURL) GetWindow() *Window {
+	if m != nil {
+		return m.Window
+	}
+	return nil
+}
+
 type ResponseInfo struct {
 	IsAvailable          bool     `protobuf:"varint,1,opt,name=isAvailable,proto3" json:"isAvailable,omitempty"`
 	AvgLatency           int64    `protobuf:"varint,2,opt,name=avgLatency,proto3" json:"avgLatency,omitempty"`
+	Status               int32    `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	FailedCheck          string   `protobuf:"bytes,4,opt,name=failedCheck,proto3" json:"failedCheck,omitempty"`
+	Reason               string   `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Error                string   `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Flapping             bool     `protobuf:"varint,7,opt,name=flapping,proto3" json:"flapping,omitempty"`
+	StateChange          float64  `protobuf:"fixed64,8,opt,name=stateChange,proto3" json:"stateChange,omitempty"`
+	Attempts             int32    `protobuf:"varint,9,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Retried              bool     `protobuf:"varint,10,opt,name=retried,proto3" json:"retried,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -104,7 +177,7 @@ func (m *ResponseInfo) Reset()         { *m = ResponseInfo{} }
 func (m *ResponseInfo) String() string { return proto.CompactTextString(m) }
 func (*ResponseInfo) ProtoMessage()    {}
 func (*ResponseInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_bedfbfc9b54e5600, []int{2}
+	return fileDescriptor_bedfbfc9b54e5600, []int{3}
 }
 
 func (m *ResponseInfo) XXX_Unmarshal(b []byte) error {
@@ -139,6 +212,62 @@ func (m *ResponseInfo) GetAvgLatency() int64 {
 	return 0
 }
 
+func (m *ResponseInfo) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *ResponseInfo) GetFailedCheck() string {
+	if m != nil {
+		return m.FailedCheck
+	}
+	return ""
+}
+
+func (m *ResponseInfo) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ResponseInfo) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ResponseInfo) GetFlapping() bool {
+	if m != nil {
+		return m.Flapping
+	}
+	return false
+}
+
+func (m *ResponseInfo) GetStateChange() float64 {
+	if m != nil {
+		return m.StateChange
+	}
+	return 0
+}
+
+func (m *ResponseInfo) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *ResponseInfo) GetRetried() bool {
+	if m != nil {
+		return m.Retried
+	}
+	return false
+}
+
 type ResponseLatency struct {
 	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
 	AvgLatency           int64    `protobuf:"varint,2,opt,name=avgLatency,proto3" json:"avgLatency,omitempty"`
@@ -151,7 +280,7 @@ func (m *ResponseLatency) Reset()         { *m = ResponseLatency{} }
 func (m *ResponseLatency) String() string { return proto.CompactTextString(m) }
 func (*ResponseLatency) ProtoMessage()    {}
 func (*ResponseLatency) Descriptor() ([]byte, []int) {
-	return fileDescriptor_bedfbfc9b54e5600, []int{3}
+	return fileDescriptor_bedfbfc9b54e5600, []int{4}
 }
 
 func (m *ResponseLatency) XXX_Unmarshal(b []byte) error {
@@ -186,158 +315,2553 @@ func (m *ResponseLatency) GetAvgLatency() int64 {
 	return 0
 }
 
-func init() {
-	proto.RegisterType((*Empty)(nil), "Empty")
-	proto.RegisterType((*RequestURL)(nil), "RequestURL")
-	proto.RegisterType((*ResponseInfo)(nil), "ResponseInfo")
-	proto.RegisterType((*ResponseLatency)(nil), "ResponseLatency")
+// Время (нс) этапов последнего запроса к url
+type ResponseBreakdown struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Timestamp            int64    `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Dns                  int64    `protobuf:"varint,3,opt,name=dns,proto3" json:"dns,omitempty"`
+	Connect              int64    `protobuf:"varint,4,opt,name=connect,proto3" json:"connect,omitempty"`
+	Tls                  int64    `protobuf:"varint,5,opt,name=tls,proto3" json:"tls,omitempty"`
+	Ttfb                 int64    `protobuf:"varint,6,opt,name=ttfb,proto3" json:"ttfb,omitempty"`
+	Transfer             int64    `protobuf:"varint,7,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	Total                int64    `protobuf:"varint,8,opt,name=total,proto3" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("grpc.proto", fileDescriptor_bedfbfc9b54e5600) }
+func (m *ResponseBreakdown) Reset()         { *m = ResponseBreakdown{} }
+func (m *ResponseBreakdown) String() string { return proto.CompactTextString(m) }
+func (*ResponseBreakdown) ProtoMessage()    {}
+func (*ResponseBreakdown) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{5}
+}
 
-var fileDescriptor_bedfbfc9b54e5600 = []byte{
-	// 218 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4a, 0x2f, 0x2a, 0x48,
-	0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x62, 0xe7, 0x62, 0x75, 0xcd, 0x2d, 0x28, 0xa9, 0x54,
-	0x92, 0xe3, 0xe2, 0x0a, 0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e, 0x09, 0x0d, 0xf2, 0x11, 0x12, 0xe0,
-	0x62, 0x2e, 0x2d, 0xca, 0x91, 0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0c, 0x02, 0x31, 0x95, 0x02, 0xb8,
-	0x78, 0x82, 0x52, 0x8b, 0x0b, 0xf2, 0xf3, 0x8a, 0x53, 0x3d, 0xf3, 0xd2, 0xf2, 0x85, 0x14, 0xb8,
-	0xb8, 0x33, 0x8b, 0x1d, 0xcb, 0x12, 0x33, 0x73, 0x12, 0x93, 0x72, 0x52, 0xc1, 0x2a, 0x39, 0x82,
-	0x90, 0x85, 0x84, 0xe4, 0xb8, 0xb8, 0x12, 0xcb, 0xd2, 0x7d, 0x12, 0x4b, 0x52, 0xf3, 0x92, 0x2b,
-	0x25, 0x98, 0x14, 0x18, 0x35, 0x98, 0x83, 0x90, 0x44, 0x94, 0x9c, 0xb9, 0xf8, 0x61, 0x26, 0x42,
-	0x85, 0x30, 0xad, 0x25, 0x64, 0x88, 0x51, 0x1f, 0x23, 0x17, 0x97, 0x6f, 0x7e, 0x5e, 0x66, 0x49,
-	0x7e, 0x51, 0x66, 0x5e, 0xba, 0x90, 0x16, 0x17, 0x97, 0x7b, 0x2a, 0xc8, 0x07, 0x60, 0x37, 0x72,
-	0xeb, 0x21, 0xbc, 0x24, 0xc5, 0xab, 0x87, 0xec, 0x7e, 0x25, 0x06, 0x21, 0x4d, 0x2e, 0x5e, 0xf7,
-	0xd4, 0x12, 0xdf, 0xc4, 0x0a, 0x98, 0xed, 0x6c, 0x7a, 0xe0, 0xa0, 0x90, 0x12, 0xd0, 0x43, 0x77,
-	0x17, 0x54, 0x69, 0x66, 0x1e, 0x41, 0xa5, 0x49, 0x6c, 0xe0, 0x70, 0x35, 0x06, 0x04, 0x00, 0x00,
-	0xff, 0xff, 0x1c, 0xfb, 0xf4, 0x47, 0x65, 0x01, 0x00, 0x00,
+func (m *ResponseBreakdown) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseBreakdown.Unmarshal(m, b)
+}
+func (m *ResponseBreakdown) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseBreakdown.Marshal(b, m, deterministic)
+}
+func (m *ResponseBreakdown) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseBreakdown.Merge(m, src)
+}
+func (m *ResponseBreakdown) XXX_Size() int {
+	return xxx_messageInfo_ResponseBreakdown.Size(m)
+}
+func (m *ResponseBreakdown) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseBreakdown.DiscardUnknown(m)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+var xxx_messageInfo_ResponseBreakdown proto.InternalMessageInfo
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *ResponseBreakdown) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
 
-// MonitoringClient is the client API for Monitoring service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type MonitoringClient interface {
-	GetURLInfo(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseInfo, error)
-	GetMaxLatency(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseLatency, error)
-	GetMinLatency(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseLatency, error)
+func (m *ResponseBreakdown) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
 }
 
-type monitoringClient struct {
-	cc *grpc.ClientConn
+func (m *ResponseBreakdown) GetDns() int64 {
+	if m != nil {
+		return m.Dns
+	}
+	return 0
 }
 
-func NewMonitoringClient(cc *grpc.ClientConn) MonitoringClient {
-	return &monitoringClient{cc}
+func (m *ResponseBreakdown) GetConnect() int64 {
+	if m != nil {
+		return m.Connect
+	}
+	return 0
 }
 
-func (c *monitoringClient) GetURLInfo(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseInfo, error) {
-	out := new(ResponseInfo)
-	err := c.cc.Invoke(ctx, "/Monitoring/GetURLInfo", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ResponseBreakdown) GetTls() int64 {
+	if m != nil {
+		return m.Tls
 	}
-	return out, nil
+	return 0
 }
 
-func (c *monitoringClient) GetMaxLatency(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseLatency, error) {
-	out := new(ResponseLatency)
-	err := c.cc.Invoke(ctx, "/Monitoring/GetMaxLatency", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ResponseBreakdown) GetTtfb() int64 {
+	if m != nil {
+		return m.Ttfb
 	}
-	return out, nil
+	return 0
 }
 
-func (c *monitoringClient) GetMinLatency(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseLatency, error) {
-	out := new(ResponseLatency)
-	err := c.cc.Invoke(ctx, "/Monitoring/GetMinLatency", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ResponseBreakdown) GetTransfer() int64 {
+	if m != nil {
+		return m.Transfer
 	}
-	return out, nil
+	return 0
 }
 
-// MonitoringServer is the server API for Monitoring service.
-type MonitoringServer interface {
-	GetURLInfo(context.Context, *RequestURL) (*ResponseInfo, error)
-	GetMaxLatency(context.Context, *Empty) (*ResponseLatency, error)
-	GetMinLatency(context.Context, *Empty) (*ResponseLatency, error)
+func (m *ResponseBreakdown) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
 }
 
-func RegisterMonitoringServer(s *grpc.Server, srv MonitoringServer) {
-	s.RegisterService(&_Monitoring_serviceDesc, srv)
+type RequestPercentiles struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Window               int64    `protobuf:"varint,2,opt,name=window,proto3" json:"window,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func _Monitoring_GetURLInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RequestURL)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(MonitoringServer).GetURLInfo(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/Monitoring/GetURLInfo",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MonitoringServer).GetURLInfo(ctx, req.(*RequestURL))
-	}
-	return interceptor(ctx, in, info, handler)
+func (m *RequestPercentiles) Reset()         { *m = RequestPercentiles{} }
+func (m *RequestPercentiles) String() string { return proto.CompactTextString(m) }
+func (*RequestPercentiles) ProtoMessage()    {}
+func (*RequestPercentiles) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{6}
 }
 
-func _Monitoring_GetMaxLatency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *RequestPercentiles) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestPercentiles.Unmarshal(m, b)
+}
+func (m *RequestPercentiles) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestPercentiles.Marshal(b, m, deterministic)
+}
+func (m *RequestPercentiles) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestPercentiles.Merge(m, src)
+}
+func (m *RequestPercentiles) XXX_Size() int {
+	return xxx_messageInfo_RequestPercentiles.Size(m)
+}
+func (m *RequestPercentiles) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestPercentiles.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestPercentiles proto.InternalMessageInfo
+
+func (m *RequestPercentiles) GetUrl() string {
+	if m != nil {
+		return m.Url
 	}
-	if interceptor == nil {
-		return srv.(MonitoringServer).GetMaxLatency(ctx, in)
+	return ""
+}
+
+func (m *RequestPercentiles) GetWindow() int64 {
+	if m != nil {
+		return m.Window
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/Monitoring/GetMaxLatency",
+	return 0
+}
+
+// Квантили времени отклика (нс)
+type ResponsePercentiles struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Count                int64    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	P50                  int64    `protobuf:"varint,3,opt,name=p50,proto3" json:"p50,omitempty"`
+	P90                  int64    `protobuf:"varint,4,opt,name=p90,proto3" json:"p90,omitempty"`
+	P95                  int64    `protobuf:"varint,5,opt,name=p95,proto3" json:"p95,omitempty"`
+	P99                  int64    `protobuf:"varint,6,opt,name=p99,proto3" json:"p99,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponsePercentiles) Reset()         { *m = ResponsePercentiles{} }
+func (m *ResponsePercentiles) String() string { return proto.CompactTextString(m) }
+func (*ResponsePercentiles) ProtoMessage()    {}
+func (*ResponsePercentiles) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{7}
+}
+
+func (m *ResponsePercentiles) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponsePercentiles.Unmarshal(m, b)
+}
+func (m *ResponsePercentiles) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponsePercentiles.Marshal(b, m, deterministic)
+}
+func (m *ResponsePercentiles) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponsePercentiles.Merge(m, src)
+}
+func (m *ResponsePercentiles) XXX_Size() int {
+	return xxx_messageInfo_ResponsePercentiles.Size(m)
+}
+func (m *ResponsePercentiles) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponsePercentiles.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponsePercentiles proto.InternalMessageInfo
+
+func (m *ResponsePercentiles) GetUrl() string {
+	if m != nil {
+		return m.Url
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MonitoringServer).GetMaxLatency(ctx, req.(*Empty))
+	return ""
+}
+
+func (m *ResponsePercentiles) GetCount() int64 {
+	if m != nil {
+		return m.Count
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _Monitoring_GetMinLatency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *ResponsePercentiles) GetP50() int64 {
+	if m != nil {
+		return m.P50
 	}
-	if interceptor == nil {
-		return srv.(MonitoringServer).GetMinLatency(ctx, in)
+	return 0
+}
+
+func (m *ResponsePercentiles) GetP90() int64 {
+	if m != nil {
+		return m.P90
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/Monitoring/GetMinLatency",
+	return 0
+}
+
+func (m *ResponsePercentiles) GetP95() int64 {
+	if m != nil {
+		return m.P95
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MonitoringServer).GetMinLatency(ctx, req.(*Empty))
+	return 0
+}
+
+func (m *ResponsePercentiles) GetP99() int64 {
+	if m != nil {
+		return m.P99
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-var _Monitoring_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "Monitoring",
-	HandlerType: (*MonitoringServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetURLInfo",
-			Handler:    _Monitoring_GetURLInfo_Handler,
-		},
+// Запрос истории времени отклика url за интервал [from, to) (нс).
+// Для получения следующей страницы нужно повторить запрос с from,
+// равным timestamp последней полученной точки + 1.
+// Если задан шаг resolution не меньше минуты, то возвращаются агрегаты
+// самого крупного уровня (минута, час, день) с периодом не больше resolution.
+type RequestHistory struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	From                 int64    `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To                   int64    `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	Limit                int32    `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Resolution           int64    `protobuf:"varint,5,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestHistory) Reset()         { *m = RequestHistory{} }
+func (m *RequestHistory) String() string { return proto.CompactTextString(m) }
+func (*RequestHistory) ProtoMessage()    {}
+func (*RequestHistory) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{8}
+}
+
+func (m *RequestHistory) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestHistory.Unmarshal(m, b)
+}
+func (m *RequestHistory) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestHistory.Marshal(b, m, deterministic)
+}
+func (m *RequestHistory) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestHistory.Merge(m, src)
+}
+func (m *RequestHistory) XXX_Size() int {
+	return xxx_messageInfo_RequestHistory.Size(m)
+}
+func (m *RequestHistory) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestHistory.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestHistory proto.InternalMessageInfo
+
+func (m *RequestHistory) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *RequestHistory) GetFrom() int64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *RequestHistory) GetTo() int64 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
+func (m *RequestHistory) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *RequestHistory) GetResolution() int64 {
+	if m != nil {
+		return m.Resolution
+	}
+	return 0
+}
+
+type Point struct {
+	Timestamp            int64    `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Latency              int64    `protobuf:"varint,2,opt,name=latency,proto3" json:"latency,omitempty"`
+	Count                int64    `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Failures             int64    `protobuf:"varint,4,opt,name=failures,proto3" json:"failures,omitempty"`
+	Min                  int64    `protobuf:"varint,5,opt,name=min,proto3" json:"min,omitempty"`
+	Max                  int64    `protobuf:"varint,6,opt,name=max,proto3" json:"max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Point) Reset()         { *m = Point{} }
+func (m *Point) String() string { return proto.CompactTextString(m) }
+func (*Point) ProtoMessage()    {}
+func (*Point) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{9}
+}
+
+func (m *Point) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Point.Unmarshal(m, b)
+}
+func (m *Point) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Point.Marshal(b, m, deterministic)
+}
+func (m *Point) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Point.Merge(m, src)
+}
+func (m *Point) XXX_Size() int {
+	return xxx_messageInfo_Point.Size(m)
+}
+func (m *Point) XXX_DiscardUnknown() {
+	xxx_messageInfo_Point.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Point proto.InternalMessageInfo
+
+func (m *Point) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Point) GetLatency() int64 {
+	if m != nil {
+		return m.Latency
+	}
+	return 0
+}
+
+func (m *Point) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *Point) GetFailures() int64 {
+	if m != nil {
+		return m.Failures
+	}
+	return 0
+}
+
+func (m *Point) GetMin() int64 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *Point) GetMax() int64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+type RequestAvailability struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Window               *Window  `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestAvailability) Reset()         { *m = RequestAvailability{} }
+func (m *RequestAvailability) String() string { return proto.CompactTextString(m) }
+func (*RequestAvailability) ProtoMessage()    {}
+func (*RequestAvailability) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{10}
+}
+
+func (m *RequestAvailability) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestAvailability.Unmarshal(m, b)
+}
+func (m *RequestAvailability) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestAvailability.Marshal(b, m, deterministic)
+}
+func (m *RequestAvailability) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestAvailability.Merge(m, src)
+}
+func (m *RequestAvailability) XXX_Size() int {
+	return xxx_messageInfo_RequestAvailability.Size(m)
+}
+func (m *RequestAvailability) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestAvailability.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestAvailability proto.InternalMessageInfo
+
+func (m *RequestAvailability) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *RequestAvailability) GetWindow() *Window {
+	if m != nil {
+		return m.Window
+	}
+	return nil
+}
+
+type ResponseAvailability struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Total                int64    `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Failures             int64    `protobuf:"varint,3,opt,name=failures,proto3" json:"failures,omitempty"`
+	Uptime               float64  `protobuf:"fixed64,4,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Observed             int64    `protobuf:"varint,5,opt,name=observed,proto3" json:"observed,omitempty"`
+	Downtime             int64    `protobuf:"varint,6,opt,name=downtime,proto3" json:"downtime,omitempty"`
+	TimeUptime           float64  `protobuf:"fixed64,7,opt,name=timeUptime,proto3" json:"timeUptime,omitempty"`
+	Excluded             int64    `protobuf:"varint,8,opt,name=excluded,proto3" json:"excluded,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponseAvailability) Reset()         { *m = ResponseAvailability{} }
+func (m *ResponseAvailability) String() string { return proto.CompactTextString(m) }
+func (*ResponseAvailability) ProtoMessage()    {}
+func (*ResponseAvailability) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{11}
+}
+
+func (m *ResponseAvailability) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseAvailability.Unmarshal(m, b)
+}
+func (m *ResponseAvailability) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseAvailability.Marshal(b, m, deterministic)
+}
+func (m *ResponseAvailability) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseAvailability.Merge(m, src)
+}
+func (m *ResponseAvailability) XXX_Size() int {
+	return xxx_messageInfo_ResponseAvailability.Size(m)
+}
+func (m *ResponseAvailability) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseAvailability.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseAvailability proto.InternalMessageInfo
+
+func (m *ResponseAvailability) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *ResponseAvailability) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *ResponseAvailability) GetFailures() int64 {
+	if m != nil {
+		return m.Failures
+	}
+	return 0
+}
+
+func (m *ResponseAvailability) GetUptime() float64 {
+	if m != nil {
+		return m.Uptime
+	}
+	return 0
+}
+
+func (m *ResponseAvailability) GetObserved() int64 {
+	if m != nil {
+		return m.Observed
+	}
+	return 0
+}
+
+func (m *ResponseAvailability) GetDowntime() int64 {
+	if m != nil {
+		return m.Downtime
+	}
+	return 0
+}
+
+func (m *ResponseAvailability) GetTimeUptime() float64 {
+	if m != nil {
+		return m.TimeUptime
+	}
+	return 0
+}
+
+func (m *ResponseAvailability) GetExcluded() int64 {
+	if m != nil {
+		return m.Excluded
+	}
+	return 0
+}
+
+type RequestSLA struct {
+	Window               *Window  `protobuf:"bytes,1,opt,name=window,proto3" json:"window,omitempty"`
+	Target               float64  `protobuf:"fixed64,2,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestSLA) Reset()         { *m = RequestSLA{} }
+func (m *RequestSLA) String() string { return proto.CompactTextString(m) }
+func (*RequestSLA) ProtoMessage()    {}
+func (*RequestSLA) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{12}
+}
+
+func (m *RequestSLA) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestSLA.Unmarshal(m, b)
+}
+func (m *RequestSLA) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestSLA.Marshal(b, m, deterministic)
+}
+func (m *RequestSLA) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestSLA.Merge(m, src)
+}
+func (m *RequestSLA) XXX_Size() int {
+	return xxx_messageInfo_RequestSLA.Size(m)
+}
+func (m *RequestSLA) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestSLA.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestSLA proto.InternalMessageInfo
+
+func (m *RequestSLA) GetWindow() *Window {
+	if m != nil {
+		return m.Window
+	}
+	return nil
+}
+
+func (m *RequestSLA) GetTarget() float64 {
+	if m != nil {
+		return m.Target
+	}
+	return 0
+}
+
+type ResponseSLA struct {
+	Target               float64    `protobuf:"fixed64,1,opt,name=target,proto3" json:"target,omitempty"`
+	Items                []*SLAItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ResponseSLA) Reset()         { *m = ResponseSLA{} }
+func (m *ResponseSLA) String() string { return proto.CompactTextString(m) }
+func (*ResponseSLA) ProtoMessage()    {}
+func (*ResponseSLA) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{13}
+}
+
+func (m *ResponseSLA) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseSLA.Unmarshal(m, b)
+}
+func (m *ResponseSLA) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseSLA.Marshal(b, m, deterministic)
+}
+func (m *ResponseSLA) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseSLA.Merge(m, src)
+}
+func (m *ResponseSLA) XXX_Size() int {
+	return xxx_messageInfo_ResponseSLA.Size(m)
+}
+func (m *ResponseSLA) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseSLA.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseSLA proto.InternalMessageInfo
+
+func (m *ResponseSLA) GetTarget() float64 {
+	if m != nil {
+		return m.Target
+	}
+	return 0
+}
+
+func (m *ResponseSLA) GetItems() []*SLAItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+// Доступность ресурса по времени (timeUptime) в сравнении с целевой
+type SLAItem struct {
+	Availability         *ResponseAvailability `protobuf:"bytes,1,opt,name=availability,proto3" json:"availability,omitempty"`
+	Met                  bool                  `protobuf:"varint,2,opt,name=met,proto3" json:"met,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *SLAItem) Reset()         { *m = SLAItem{} }
+func (m *SLAItem) String() string { return proto.CompactTextString(m) }
+func (*SLAItem) ProtoMessage()    {}
+func (*SLAItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{14}
+}
+
+func (m *SLAItem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SLAItem.Unmarshal(m, b)
+}
+func (m *SLAItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SLAItem.Marshal(b, m, deterministic)
+}
+func (m *SLAItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SLAItem.Merge(m, src)
+}
+func (m *SLAItem) XXX_Size() int {
+	return xxx_messageInfo_SLAItem.Size(m)
+}
+func (m *SLAItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_SLAItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SLAItem proto.InternalMessageInfo
+
+func (m *SLAItem) GetAvailability() *ResponseAvailability {
+	if m != nil {
+		return m.Availability
+	}
+	return nil
+}
+
+func (m *SLAItem) GetMet() bool {
+	if m != nil {
+		return m.Met
+	}
+	return false
+}
+
+type RequestIncidents struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Window               *Window  `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestIncidents) Reset()         { *m = RequestIncidents{} }
+func (m *RequestIncidents) String() string { return proto.CompactTextString(m) }
+func (*RequestIncidents) ProtoMessage()    {}
+func (*RequestIncidents) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{15}
+}
+
+func (m *RequestIncidents) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestIncidents.Unmarshal(m, b)
+}
+func (m *RequestIncidents) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestIncidents.Marshal(b, m, deterministic)
+}
+func (m *RequestIncidents) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestIncidents.Merge(m, src)
+}
+func (m *RequestIncidents) XXX_Size() int {
+	return xxx_messageInfo_RequestIncidents.Size(m)
+}
+func (m *RequestIncidents) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestIncidents.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestIncidents proto.InternalMessageInfo
+
+func (m *RequestIncidents) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *RequestIncidents) GetWindow() *Window {
+	if m != nil {
+		return m.Window
+	}
+	return nil
+}
+
+type ResponseIncidents struct {
+	Incidents            []*Incident `protobuf:"bytes,1,rep,name=incidents,proto3" json:"incidents,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ResponseIncidents) Reset()         { *m = ResponseIncidents{} }
+func (m *ResponseIncidents) String() string { return proto.CompactTextString(m) }
+func (*ResponseIncidents) ProtoMessage()    {}
+func (*ResponseIncidents) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{16}
+}
+
+func (m *ResponseIncidents) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseIncidents.Unmarshal(m, b)
+}
+func (m *ResponseIncidents) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseIncidents.Marshal(b, m, deterministic)
+}
+func (m *ResponseIncidents) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseIncidents.Merge(m, src)
+}
+func (m *ResponseIncidents) XXX_Size() int {
+	return xxx_messageInfo_ResponseIncidents.Size(m)
+}
+func (m *ResponseIncidents) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseIncidents.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseIncidents proto.InternalMessageInfo
+
+func (m *ResponseIncidents) GetIncidents() []*Incident {
+	if m != nil {
+		return m.Incidents
+	}
+	return nil
+}
+
+// Период недоступности ресурса
+type Incident struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Start                int64    `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End                  int64    `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	Duration             int64    `protobuf:"varint,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Reason               string   `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Error                string   `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Incident) Reset()         { *m = Incident{} }
+func (m *Incident) String() string { return proto.CompactTextString(m) }
+func (*Incident) ProtoMessage()    {}
+func (*Incident) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{17}
+}
+
+func (m *Incident) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Incident.Unmarshal(m, b)
+}
+func (m *Incident) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Incident.Marshal(b, m, deterministic)
+}
+func (m *Incident) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Incident.Merge(m, src)
+}
+func (m *Incident) XXX_Size() int {
+	return xxx_messageInfo_Incident.Size(m)
+}
+func (m *Incident) XXX_DiscardUnknown() {
+	xxx_messageInfo_Incident.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Incident proto.InternalMessageInfo
+
+func (m *Incident) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Incident) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *Incident) GetEnd() int64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *Incident) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *Incident) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Incident) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type RequestID struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestID) Reset()         { *m = RequestID{} }
+func (m *RequestID) String() string { return proto.CompactTextString(m) }
+func (*RequestID) ProtoMessage()    {}
+func (*RequestID) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{18}
+}
+
+func (m *RequestID) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestID.Unmarshal(m, b)
+}
+func (m *RequestID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestID.Marshal(b, m, deterministic)
+}
+func (m *RequestID) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestID.Merge(m, src)
+}
+func (m *RequestID) XXX_Size() int {
+	return xxx_messageInfo_RequestID.Size(m)
+}
+func (m *RequestID) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestID.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestID proto.InternalMessageInfo
+
+func (m *RequestID) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+// Отключение оповещений для url на интервал [start, end) (нс)
+type Silence struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Start                int64    `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	End                  int64    `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	Creator              string   `protobuf:"bytes,5,opt,name=creator,proto3" json:"creator,omitempty"`
+	Comment              string   `protobuf:"bytes,6,opt,name=comment,proto3" json:"comment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Silence) Reset()         { *m = Silence{} }
+func (m *Silence) String() string { return proto.CompactTextString(m) }
+func (*Silence) ProtoMessage()    {}
+func (*Silence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{19}
+}
+
+func (m *Silence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Silence.Unmarshal(m, b)
+}
+func (m *Silence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Silence.Marshal(b, m, deterministic)
+}
+func (m *Silence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Silence.Merge(m, src)
+}
+func (m *Silence) XXX_Size() int {
+	return xxx_messageInfo_Silence.Size(m)
+}
+func (m *Silence) XXX_DiscardUnknown() {
+	xxx_messageInfo_Silence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Silence proto.InternalMessageInfo
+
+func (m *Silence) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Silence) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Silence) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *Silence) GetEnd() int64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *Silence) GetCreator() string {
+	if m != nil {
+		return m.Creator
+	}
+	return ""
+}
+
+func (m *Silence) GetComment() string {
+	if m != nil {
+		return m.Comment
+	}
+	return ""
+}
+
+type ResponseSilences struct {
+	Silences             []*Silence `protobuf:"bytes,1,rep,name=silences,proto3" json:"silences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ResponseSilences) Reset()         { *m = ResponseSilences{} }
+func (m *ResponseSilences) String() string { return proto.CompactTextString(m) }
+func (*ResponseSilences) ProtoMessage()    {}
+func (*ResponseSilences) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{20}
+}
+
+func (m *ResponseSilences) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseSilences.Unmarshal(m, b)
+}
+func (m *ResponseSilences) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseSilences.Marshal(b, m, deterministic)
+}
+func (m *ResponseSilences) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseSilences.Merge(m, src)
+}
+func (m *ResponseSilences) XXX_Size() int {
+	return xxx_messageInfo_ResponseSilences.Size(m)
+}
+func (m *ResponseSilences) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseSilences.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseSilences proto.InternalMessageInfo
+
+func (m *ResponseSilences) GetSilences() []*Silence {
+	if m != nil {
+		return m.Silences
+	}
+	return nil
+}
+
+// Окно обслуживания длительностью duration (нс), которое начинается в start (нс)
+// и повторяется каждые every (нс) до until (нс). Проверки во время обслуживания
+// не учитываются при расчете доступности, оповещения не отправляются.
+type Maintenance struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Start                int64    `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	Duration             int64    `protobuf:"varint,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Every                int64    `protobuf:"varint,5,opt,name=every,proto3" json:"every,omitempty"`
+	Until                int64    `protobuf:"varint,6,opt,name=until,proto3" json:"until,omitempty"`
+	Creator              string   `protobuf:"bytes,7,opt,name=creator,proto3" json:"creator,omitempty"`
+	Comment              string   `protobuf:"bytes,8,opt,name=comment,proto3" json:"comment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Maintenance) Reset()         { *m = Maintenance{} }
+func (m *Maintenance) String() string { return proto.CompactTextString(m) }
+func (*Maintenance) ProtoMessage()    {}
+func (*Maintenance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{21}
+}
+
+func (m *Maintenance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Maintenance.Unmarshal(m, b)
+}
+func (m *Maintenance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Maintenance.Marshal(b, m, deterministic)
+}
+func (m *Maintenance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Maintenance.Merge(m, src)
+}
+func (m *Maintenance) XXX_Size() int {
+	return xxx_messageInfo_Maintenance.Size(m)
+}
+func (m *Maintenance) XXX_DiscardUnknown() {
+	xxx_messageInfo_Maintenance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Maintenance proto.InternalMessageInfo
+
+func (m *Maintenance) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Maintenance) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Maintenance) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *Maintenance) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *Maintenance) GetEvery() int64 {
+	if m != nil {
+		return m.Every
+	}
+	return 0
+}
+
+func (m *Maintenance) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+func (m *Maintenance) GetCreator() string {
+	if m != nil {
+		return m.Creator
+	}
+	return ""
+}
+
+func (m *Maintenance) GetComment() string {
+	if m != nil {
+		return m.Comment
+	}
+	return ""
+}
+
+type ResponseMaintenance struct {
+	Maintenance          []*Maintenance `protobuf:"bytes,1,rep,name=maintenance,proto3" json:"maintenance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ResponseMaintenance) Reset()         { *m = ResponseMaintenance{} }
+func (m *ResponseMaintenance) String() string { return proto.CompactTextString(m) }
+func (*ResponseMaintenance) ProtoMessage()    {}
+func (*ResponseMaintenance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{22}
+}
+
+func (m *ResponseMaintenance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseMaintenance.Unmarshal(m, b)
+}
+func (m *ResponseMaintenance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseMaintenance.Marshal(b, m, deterministic)
+}
+func (m *ResponseMaintenance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseMaintenance.Merge(m, src)
+}
+func (m *ResponseMaintenance) XXX_Size() int {
+	return xxx_messageInfo_ResponseMaintenance.Size(m)
+}
+func (m *ResponseMaintenance) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseMaintenance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseMaintenance proto.InternalMessageInfo
+
+func (m *ResponseMaintenance) GetMaintenance() []*Maintenance {
+	if m != nil {
+		return m.Maintenance
+	}
+	return nil
+}
+
+// Ресурс для опроса и правила проверки его ответа.
+// Поля 2-6 относятся к http(s) ресурсам, send и expect - к tcp://host:port,
+// record и values - к dns://resolver[:port]/name. Для grpc://host:port/service
+// и grpcs:// (с tls) вызывается grpc.health.v1.Health/Check, дополнительных полей нет.
+type Target struct {
+	Url                  string            `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Method               string            `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Body                 string            `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	Status               []string          `protobuf:"bytes,4,rep,name=status,proto3" json:"status,omitempty"`
+	Checks               []*Check          `protobuf:"bytes,5,rep,name=checks,proto3" json:"checks,omitempty"`
+	Headers              map[string]string `protobuf:"bytes,6,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Tags                 []string          `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	Interval             int64             `protobuf:"varint,8,opt,name=interval,proto3" json:"interval,omitempty"`
+	Timeout              int64             `protobuf:"varint,9,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Retry                *Retry            `protobuf:"bytes,10,opt,name=retry,proto3" json:"retry,omitempty"`
+	Send                 string            `protobuf:"bytes,11,opt,name=send,proto3" json:"send,omitempty"`
+	Expect               string            `protobuf:"bytes,12,opt,name=expect,proto3" json:"expect,omitempty"`
+	Record               string            `protobuf:"bytes,13,opt,name=record,proto3" json:"record,omitempty"`
+	Values               []string          `protobuf:"bytes,14,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Target) Reset()         { *m = Target{} }
+func (m *Target) String() string { return proto.CompactTextString(m) }
+func (*Target) ProtoMessage()    {}
+func (*Target) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{23}
+}
+
+func (m *Target) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Target.Unmarshal(m, b)
+}
+func (m *Target) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Target.Marshal(b, m, deterministic)
+}
+func (m *Target) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Target.Merge(m, src)
+}
+func (m *Target) XXX_Size() int {
+	return xxx_messageInfo_Target.Size(m)
+}
+func (m *Target) XXX_DiscardUnknown() {
+	xxx_messageInfo_Target.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Target proto.InternalMessageInfo
+
+func (m *Target) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Target) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *Target) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+func (m *Target) GetStatus() []string {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *Target) GetChecks() []*Check {
+	if m != nil {
+		return m.Checks
+	}
+	return nil
+}
+
+func (m *Target) GetHeaders() map[string]string {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *Target) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Target) GetInterval() int64 {
+	if m != nil {
+		return m.Interval
+	}
+	return 0
+}
+
+func (m *Target) GetTimeout() int64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+func (m *Target) GetRetry() *Retry {
+	if m != nil {
+		return m.Retry
+	}
+	return nil
+}
+
+func (m *Target) GetSend() string {
+	if m != nil {
+		return m.Send
+	}
+	return ""
+}
+
+func (m *Target) GetExpect() string {
+	if m != nil {
+		return m.Expect
+	}
+	return ""
+}
+
+func (m *Target) GetRecord() string {
+	if m != nil {
+		return m.Record
+	}
+	return ""
+}
+
+func (m *Target) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// Повтор неудачной проверки в рамках одного опроса
+type Retry struct {
+	Attempts             int32    `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Backoff              int64    `protobuf:"varint,2,opt,name=backoff,proto3" json:"backoff,omitempty"`
+	On                   []string `protobuf:"bytes,3,rep,name=on,proto3" json:"on,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Retry) Reset()         { *m = Retry{} }
+func (m *Retry) String() string { return proto.CompactTextString(m) }
+func (*Retry) ProtoMessage()    {}
+func (*Retry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{24}
+}
+
+func (m *Retry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Retry.Unmarshal(m, b)
+}
+func (m *Retry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Retry.Marshal(b, m, deterministic)
+}
+func (m *Retry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Retry.Merge(m, src)
+}
+func (m *Retry) XXX_Size() int {
+	return xxx_messageInfo_Retry.Size(m)
+}
+func (m *Retry) XXX_DiscardUnknown() {
+	xxx_messageInfo_Retry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Retry proto.InternalMessageInfo
+
+func (m *Retry) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *Retry) GetBackoff() int64 {
+	if m != nil {
+		return m.Backoff
+	}
+	return 0
+}
+
+func (m *Retry) GetOn() []string {
+	if m != nil {
+		return m.On
+	}
+	return nil
+}
+
+// Проверка тела ответа
+type Check struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Value                string   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Check) Reset()         { *m = Check{} }
+func (m *Check) String() string { return proto.CompactTextString(m) }
+func (*Check) ProtoMessage()    {}
+func (*Check) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{25}
+}
+
+func (m *Check) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Check.Unmarshal(m, b)
+}
+func (m *Check) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Check.Marshal(b, m, deterministic)
+}
+func (m *Check) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Check.Merge(m, src)
+}
+func (m *Check) XXX_Size() int {
+	return xxx_messageInfo_Check.Size(m)
+}
+func (m *Check) XXX_DiscardUnknown() {
+	xxx_messageInfo_Check.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Check proto.InternalMessageInfo
+
+func (m *Check) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Check) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Check) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type RequestTarget struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestTarget) Reset()         { *m = RequestTarget{} }
+func (m *RequestTarget) String() string { return proto.CompactTextString(m) }
+func (*RequestTarget) ProtoMessage()    {}
+func (*RequestTarget) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{26}
+}
+
+func (m *RequestTarget) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestTarget.Unmarshal(m, b)
+}
+func (m *RequestTarget) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestTarget.Marshal(b, m, deterministic)
+}
+func (m *RequestTarget) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestTarget.Merge(m, src)
+}
+func (m *RequestTarget) XXX_Size() int {
+	return xxx_messageInfo_RequestTarget.Size(m)
+}
+func (m *RequestTarget) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestTarget.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestTarget proto.InternalMessageInfo
+
+func (m *RequestTarget) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+type ResponseTargets struct {
+	Targets              []*Target `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ResponseTargets) Reset()         { *m = ResponseTargets{} }
+func (m *ResponseTargets) String() string { return proto.CompactTextString(m) }
+func (*ResponseTargets) ProtoMessage()    {}
+func (*ResponseTargets) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{27}
+}
+
+func (m *ResponseTargets) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseTargets.Unmarshal(m, b)
+}
+func (m *ResponseTargets) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseTargets.Marshal(b, m, deterministic)
+}
+func (m *ResponseTargets) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseTargets.Merge(m, src)
+}
+func (m *ResponseTargets) XXX_Size() int {
+	return xxx_messageInfo_ResponseTargets.Size(m)
+}
+func (m *ResponseTargets) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseTargets.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseTargets proto.InternalMessageInfo
+
+func (m *ResponseTargets) GetTargets() []*Target {
+	if m != nil {
+		return m.Targets
+	}
+	return nil
+}
+
+// Счетчики опросов с момента запуска сервера. Рост skipped, queued и late
+// означает, что воркеров не хватает для опроса ресурсов с их периодом.
+type ResponsePollerStats struct {
+	Scheduled            uint64   `protobuf:"varint,1,opt,name=scheduled,proto3" json:"scheduled,omitempty"`
+	Skipped              uint64   `protobuf:"varint,2,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Queued               uint64   `protobuf:"varint,3,opt,name=queued,proto3" json:"queued,omitempty"`
+	Late                 uint64   `protobuf:"varint,4,opt,name=late,proto3" json:"late,omitempty"`
+	InFlight             int32    `protobuf:"varint,5,opt,name=inFlight,proto3" json:"inFlight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponsePollerStats) Reset()         { *m = ResponsePollerStats{} }
+func (m *ResponsePollerStats) String() string { return proto.CompactTextString(m) }
+func (*ResponsePollerStats) ProtoMessage()    {}
+func (*ResponsePollerStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{28}
+}
+
+func (m *ResponsePollerStats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponsePollerStats.Unmarshal(m, b)
+}
+func (m *ResponsePollerStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponsePollerStats.Marshal(b, m, deterministic)
+}
+func (m *ResponsePollerStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponsePollerStats.Merge(m, src)
+}
+func (m *ResponsePollerStats) XXX_Size() int {
+	return xxx_messageInfo_ResponsePollerStats.Size(m)
+}
+func (m *ResponsePollerStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponsePollerStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponsePollerStats proto.InternalMessageInfo
+
+func (m *ResponsePollerStats) GetScheduled() uint64 {
+	if m != nil {
+		return m.Scheduled
+	}
+	return 0
+}
+
+func (m *ResponsePollerStats) GetSkipped() uint64 {
+	if m != nil {
+		return m.Skipped
+	}
+	return 0
+}
+
+func (m *ResponsePollerStats) GetQueued() uint64 {
+	if m != nil {
+		return m.Queued
+	}
+	return 0
+}
+
+func (m *ResponsePollerStats) GetLate() uint64 {
+	if m != nil {
+		return m.Late
+	}
+	return 0
+}
+
+func (m *ResponsePollerStats) GetInFlight() int32 {
+	if m != nil {
+		return m.InFlight
+	}
+	return 0
+}
+
+// Сертификаты https ресурса, полученные при последней проверке
+type ResponseCertificate struct {
+	Url                  string         `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Timestamp            int64          `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Chain                []*Certificate `protobuf:"bytes,3,rep,name=chain,proto3" json:"chain,omitempty"`
+	Error                string         `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Warnings             []string       `protobuf:"bytes,5,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Expires              int64          `protobuf:"varint,6,opt,name=expires,proto3" json:"expires,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ResponseCertificate) Reset()         { *m = ResponseCertificate{} }
+func (m *ResponseCertificate) String() string { return proto.CompactTextString(m) }
+func (*ResponseCertificate) ProtoMessage()    {}
+func (*ResponseCertificate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{29}
+}
+
+func (m *ResponseCertificate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResponseCertificate.Unmarshal(m, b)
+}
+func (m *ResponseCertificate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResponseCertificate.Marshal(b, m, deterministic)
+}
+func (m *ResponseCertificate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseCertificate.Merge(m, src)
+}
+func (m *ResponseCertificate) XXX_Size() int {
+	return xxx_messageInfo_ResponseCertificate.Size(m)
+}
+func (m *ResponseCertificate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseCertificate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseCertificate proto.InternalMessageInfo
+
+func (m *ResponseCertificate) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *ResponseCertificate) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *ResponseCertificate) GetChain() []*Certificate {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
+}
+
+func (m *ResponseCertificate) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ResponseCertificate) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *ResponseCertificate) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+type Certificate struct {
+	Subject              string   `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Issuer               string   `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	DnsNames             []string `protobuf:"bytes,3,rep,name=dnsNames,proto3" json:"dnsNames,omitempty"`
+	NotBefore            int64    `protobuf:"varint,4,opt,name=notBefore,proto3" json:"notBefore,omitempty"`
+	NotAfter             int64    `protobuf:"varint,5,opt,name=notAfter,proto3" json:"notAfter,omitempty"`
+	SignatureAlgorithm   string   `protobuf:"bytes,6,opt,name=signatureAlgorithm,proto3" json:"signatureAlgorithm,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Certificate) Reset()         { *m = Certificate{} }
+func (m *Certificate) String() string { return proto.CompactTextString(m) }
+func (*Certificate) ProtoMessage()    {}
+func (*Certificate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bedfbfc9b54e5600, []int{30}
+}
+
+func (m *Certificate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Certificate.Unmarshal(m, b)
+}
+func (m *Certificate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Certificate.Marshal(b, m, deterministic)
+}
+func (m *Certificate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Certificate.Merge(m, src)
+}
+func (m *Certificate) XXX_Size() int {
+	return xxx_messageInfo_Certificate.Size(m)
+}
+func (m *Certificate) XXX_DiscardUnknown() {
+	xxx_messageInfo_Certificate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Certificate proto.InternalMessageInfo
+
+func (m *Certificate) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *Certificate) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *Certificate) GetDnsNames() []string {
+	if m != nil {
+		return m.DnsNames
+	}
+	return nil
+}
+
+func (m *Certificate) GetNotBefore() int64 {
+	if m != nil {
+		return m.NotBefore
+	}
+	return 0
+}
+
+func (m *Certificate) GetNotAfter() int64 {
+	if m != nil {
+		return m.NotAfter
+	}
+	return 0
+}
+
+func (m *Certificate) GetSignatureAlgorithm() string {
+	if m != nil {
+		return m.SignatureAlgorithm
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "Empty")
+	proto.RegisterType((*Window)(nil), "Window")
+	proto.RegisterType((*RequestURL)(nil), "RequestURL")
+	proto.RegisterType((*ResponseInfo)(nil), "ResponseInfo")
+	proto.RegisterType((*ResponseLatency)(nil), "ResponseLatency")
+	proto.RegisterType((*ResponseBreakdown)(nil), "ResponseBreakdown")
+	proto.RegisterType((*RequestPercentiles)(nil), "RequestPercentiles")
+	proto.RegisterType((*ResponsePercentiles)(nil), "ResponsePercentiles")
+	proto.RegisterType((*RequestHistory)(nil), "RequestHistory")
+	proto.RegisterType((*Point)(nil), "Point")
+	proto.RegisterType((*RequestAvailability)(nil), "RequestAvailability")
+	proto.RegisterType((*ResponseAvailability)(nil), "ResponseAvailability")
+	proto.RegisterType((*RequestSLA)(nil), "RequestSLA")
+	proto.RegisterType((*ResponseSLA)(nil), "ResponseSLA")
+	proto.RegisterType((*SLAItem)(nil), "SLAItem")
+	proto.RegisterType((*RequestIncidents)(nil), "RequestIncidents")
+	proto.RegisterType((*ResponseIncidents)(nil), "ResponseIncidents")
+	proto.RegisterType((*Incident)(nil), "Incident")
+	proto.RegisterType((*RequestID)(nil), "RequestID")
+	proto.RegisterType((*Silence)(nil), "Silence")
+	proto.RegisterType((*ResponseSilences)(nil), "ResponseSilences")
+	proto.RegisterType((*Maintenance)(nil), "Maintenance")
+	proto.RegisterType((*ResponseMaintenance)(nil), "ResponseMaintenance")
+	proto.RegisterType((*Target)(nil), "Target")
+	proto.RegisterMapType((map[string]string)(nil), "Target.HeadersEntry")
+	proto.RegisterType((*Retry)(nil), "Retry")
+	proto.RegisterType((*Check)(nil), "Check")
+	proto.RegisterType((*RequestTarget)(nil), "RequestTarget")
+	proto.RegisterType((*ResponseTargets)(nil), "ResponseTargets")
+	proto.RegisterType((*ResponsePollerStats)(nil), "ResponsePollerStats")
+	proto.RegisterType((*ResponseCertificate)(nil), "ResponseCertificate")
+	proto.RegisterType((*Certificate)(nil), "Certificate")
+}
+
+func init() { proto.RegisterFile("grpc.proto", fileDescriptor_bedfbfc9b54e5600) }
+
+var fileDescriptor_bedfbfc9b54e5600 = []byte{
+	// 1780 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0x5f, 0x6f, 0x1c, 0x49,
+	0x11, 0x67, 0x76, 0xbc, 0xbb, 0xde, 0x5a, 0xdb, 0x49, 0x26, 0xbe, 0x68, 0xb4, 0x1c, 0xc1, 0x37,
+	0x9c, 0x48, 0x38, 0xa1, 0x21, 0x0a, 0xe4, 0xb8, 0x9c, 0xd0, 0x81, 0x93, 0x18, 0x27, 0x92, 0x83,
+	0x4e, 0x6d, 0x02, 0xcf, 0xed, 0x9d, 0xda, 0x75, 0xe3, 0x99, 0xe9, 0xb9, 0x9e, 0x1e, 0xc7, 0x2b,
+	0xf1, 0x8a, 0x78, 0xe3, 0x8d, 0x6f, 0x81, 0x78, 0xe3, 0x03, 0xf0, 0x80, 0xf8, 0x26, 0x7c, 0x01,
+	0xbe, 0x00, 0xaa, 0xfe, 0x33, 0x33, 0x6b, 0x6f, 0xee, 0x1f, 0x4f, 0xdb, 0xbf, 0xea, 0x9a, 0xee,
+	0xaa, 0x5f, 0x57, 0x55, 0x57, 0x2f, 0xc0, 0x52, 0x55, 0xf3, 0xb4, 0x52, 0x52, 0xcb, 0x64, 0x0c,
+	0xc3, 0xa3, 0xa2, 0xd2, 0xab, 0xe4, 0x57, 0x30, 0xfa, 0xbd, 0x28, 0x33, 0xf9, 0x36, 0x8a, 0x60,
+	0x2b, 0xe7, 0xb5, 0x8e, 0x83, 0x83, 0xe0, 0x61, 0xc8, 0xcc, 0x98, 0x64, 0x0b, 0x25, 0x8b, 0x78,
+	0x60, 0x65, 0x34, 0x8e, 0xf6, 0x60, 0xa0, 0x65, 0x1c, 0x1a, 0xc9, 0x40, 0xcb, 0xe4, 0x97, 0x00,
+	0x0c, 0xbf, 0x68, 0xb0, 0xd6, 0x6f, 0xd8, 0x49, 0x74, 0x1b, 0xc2, 0x46, 0xe5, 0x66, 0x91, 0x09,
+	0xa3, 0x61, 0xf4, 0x7d, 0x18, 0xbd, 0x35, 0x3b, 0x98, 0x55, 0xa6, 0x8f, 0xc7, 0xa9, 0xdd, 0x90,
+	0x39, 0x71, 0xf2, 0xb7, 0x01, 0xec, 0x30, 0xac, 0x2b, 0x59, 0xd6, 0xf8, 0xaa, 0x5c, 0xc8, 0xe8,
+	0x00, 0xa6, 0xa2, 0x3e, 0xbc, 0xe4, 0x22, 0xe7, 0x67, 0x39, 0x9a, 0xb5, 0xb6, 0x59, 0x5f, 0x14,
+	0xdd, 0x07, 0xe0, 0x97, 0xcb, 0x13, 0xae, 0xb1, 0x9c, 0xaf, 0x9c, 0x75, 0x3d, 0x49, 0x74, 0x0f,
+	0x46, 0xb5, 0xe6, 0xba, 0xa9, 0x8d, 0x9d, 0x43, 0xe6, 0x10, 0xad, 0xbc, 0xe0, 0x22, 0xc7, 0xec,
+	0xf9, 0x39, 0xce, 0x2f, 0xe2, 0x2d, 0x63, 0x65, 0x5f, 0x44, 0x5f, 0x2a, 0xe4, 0xb5, 0x2c, 0xe3,
+	0xa1, 0x99, 0x74, 0x28, 0xda, 0x87, 0x21, 0x2a, 0x25, 0x55, 0x3c, 0x32, 0x62, 0x0b, 0xa2, 0x19,
+	0x6c, 0x2f, 0x72, 0x5e, 0x55, 0xa2, 0x5c, 0xc6, 0x63, 0x63, 0x66, 0x8b, 0x69, 0x2f, 0xda, 0x15,
+	0x9f, 0x9f, 0xf3, 0x72, 0x89, 0xf1, 0xf6, 0x41, 0xf0, 0x30, 0x60, 0x7d, 0x11, 0x7d, 0xcd, 0xb5,
+	0xc6, 0xa2, 0xd2, 0x75, 0x3c, 0x31, 0x76, 0xb6, 0x38, 0x8a, 0x61, 0xac, 0x50, 0x2b, 0x81, 0x59,
+	0x0c, 0x66, 0x61, 0x0f, 0x93, 0xe7, 0x70, 0xcb, 0xb3, 0xe5, 0xdd, 0xbd, 0x49, 0xfa, 0x57, 0x10,
+	0x94, 0xfc, 0x3b, 0x80, 0x3b, 0x7e, 0x95, 0x67, 0x0a, 0xf9, 0x45, 0x26, 0xdf, 0x96, 0x1b, 0xd6,
+	0x79, 0x1f, 0x26, 0x5a, 0x14, 0x58, 0x6b, 0x5e, 0x54, 0x6e, 0x99, 0x4e, 0x40, 0xfa, 0x59, 0x59,
+	0xbb, 0x58, 0xa0, 0x21, 0x99, 0x3d, 0x97, 0x65, 0x89, 0x73, 0x6d, 0xc8, 0x0d, 0x99, 0x87, 0xa4,
+	0xab, 0xf3, 0xda, 0xb0, 0x1a, 0x32, 0x1a, 0x52, 0x70, 0x69, 0xbd, 0x38, 0x33, 0x8c, 0x86, 0xcc,
+	0x8c, 0x89, 0x12, 0xad, 0x78, 0x59, 0x2f, 0x50, 0x19, 0x42, 0x43, 0xd6, 0x62, 0x3a, 0x02, 0x2d,
+	0x35, 0xcf, 0x0d, 0x95, 0x21, 0xb3, 0x20, 0xf9, 0x0c, 0x22, 0x17, 0x7e, 0x9f, 0xa3, 0x9a, 0x63,
+	0xa9, 0x45, 0x8e, 0xf5, 0x06, 0x4f, 0xee, 0xad, 0x85, 0x61, 0xd8, 0x46, 0xdf, 0x9f, 0x02, 0xb8,
+	0xeb, 0x99, 0xf8, 0xf2, 0x15, 0xf6, 0x61, 0x38, 0x97, 0x4d, 0xa9, 0xdd, 0x02, 0x16, 0x90, 0x5e,
+	0xf5, 0xe4, 0x91, 0xe7, 0xa0, 0x7a, 0xf2, 0xc8, 0x48, 0x9e, 0x3e, 0x72, 0xfe, 0xd3, 0xd0, 0x4a,
+	0x9e, 0x78, 0xdf, 0xab, 0xa7, 0x4f, 0xac, 0xe4, 0xa9, 0x73, 0x9d, 0x86, 0xc9, 0x1f, 0x61, 0xcf,
+	0xf9, 0xf1, 0x52, 0xd4, 0x5a, 0xaa, 0x4d, 0xa7, 0xfa, 0x35, 0xd2, 0x91, 0xac, 0xcc, 0x45, 0x21,
+	0x2c, 0xff, 0x43, 0x66, 0x01, 0xc5, 0x83, 0xc2, 0x5a, 0xe6, 0x8d, 0x16, 0x2e, 0xb4, 0x43, 0xd6,
+	0x93, 0x24, 0x7f, 0x0d, 0x60, 0xf8, 0xb9, 0x14, 0xa5, 0x5e, 0x3f, 0xf1, 0xe0, 0xfa, 0x89, 0xc7,
+	0x30, 0xce, 0xd7, 0x82, 0xca, 0xc3, 0x8e, 0x9d, 0xb0, 0xcf, 0x0e, 0x25, 0x08, 0x17, 0x79, 0xa3,
+	0xb0, 0x76, 0x84, 0xb4, 0x98, 0xfc, 0x2b, 0x84, 0x37, 0x86, 0x86, 0x46, 0xc2, 0xaf, 0x3c, 0x2b,
+	0x05, 0xbf, 0x4a, 0x5e, 0xc2, 0x5d, 0xc7, 0x8a, 0x4b, 0x7e, 0x91, 0x0b, 0xbd, 0xfa, 0x36, 0x55,
+	0xe6, 0x3f, 0x01, 0xec, 0xfb, 0x73, 0xfe, 0x8a, 0xb5, 0xda, 0x40, 0x1b, 0xf4, 0x02, 0x6d, 0xcd,
+	0x95, 0xf0, 0x9a, 0x2b, 0xf7, 0x60, 0xd4, 0x54, 0xc4, 0x92, 0x71, 0x32, 0x60, 0x0e, 0xd1, 0x37,
+	0xf2, 0xac, 0x46, 0x75, 0x89, 0x99, 0xf3, 0xb3, 0xc5, 0x34, 0x47, 0x49, 0x67, 0xbe, 0xb2, 0x1e,
+	0xb7, 0x98, 0x8e, 0x8b, 0x7e, 0xdf, 0xd8, 0x35, 0xc7, 0x66, 0xcd, 0x9e, 0x84, 0xbe, 0xc5, 0xab,
+	0x79, 0xde, 0x64, 0x98, 0xb9, 0x6c, 0x68, 0x71, 0x72, 0xd4, 0xd6, 0xe3, 0xd3, 0x93, 0xc3, 0x1e,
+	0x2f, 0xc1, 0x46, 0x5e, 0xc8, 0x74, 0xcd, 0xd5, 0x12, 0x6d, 0x58, 0x07, 0xcc, 0xa1, 0xe4, 0x08,
+	0xa6, 0x9e, 0x2e, 0x5a, 0xa7, 0x53, 0x0b, 0xfa, 0x6a, 0xd1, 0x7d, 0x18, 0x0a, 0x8d, 0x45, 0x1d,
+	0x0f, 0x0e, 0xc2, 0x87, 0xd3, 0xc7, 0xdb, 0xe9, 0xe9, 0xc9, 0xe1, 0x2b, 0x8d, 0x05, 0xb3, 0xe2,
+	0xe4, 0x77, 0x30, 0x76, 0x92, 0xe8, 0x29, 0xec, 0xf0, 0x1e, 0xf1, 0xce, 0xa0, 0xf7, 0xd2, 0x4d,
+	0xa7, 0xc2, 0x76, 0xf8, 0xb5, 0x33, 0x2a, 0x9c, 0x85, 0xdb, 0x8c, 0x86, 0xc9, 0x11, 0xdc, 0x76,
+	0x5e, 0xbe, 0x2a, 0xe7, 0x22, 0xc3, 0x52, 0xd7, 0xdf, 0x26, 0x2a, 0x7e, 0xd1, 0x95, 0xc1, 0x6e,
+	0x9d, 0x07, 0x30, 0x11, 0x1e, 0xc4, 0x81, 0xf1, 0x6b, 0x92, 0xfa, 0x69, 0xd6, 0xcd, 0x25, 0x7f,
+	0x09, 0x60, 0xdb, 0xcb, 0x37, 0xc7, 0x51, 0xad, 0xb9, 0x6a, 0x0b, 0x86, 0x01, 0xa4, 0x87, 0x65,
+	0xe6, 0x0b, 0x06, 0x96, 0x36, 0x12, 0x1a, 0xc5, 0x4d, 0x6a, 0xba, 0x24, 0xf1, 0xf8, 0x9b, 0xdd,
+	0x47, 0xc9, 0x77, 0x61, 0xe2, 0x59, 0x79, 0x41, 0x95, 0x41, 0x64, 0xc6, 0x9e, 0x2d, 0x36, 0x10,
+	0x59, 0xf2, 0xe7, 0x00, 0xc6, 0xa7, 0x22, 0xc7, 0x72, 0x8e, 0xd7, 0xe7, 0xbc, 0xf1, 0x83, 0x0d,
+	0xc6, 0x87, 0x1b, 0x8c, 0xdf, 0xea, 0x8c, 0xa7, 0x8a, 0xaf, 0x90, 0x6b, 0xa9, 0x9c, 0x85, 0x1e,
+	0x9a, 0x19, 0x59, 0x14, 0x58, 0x6a, 0x67, 0xa4, 0x87, 0xc9, 0x27, 0x70, 0xdb, 0xb3, 0xee, 0x0c,
+	0xaa, 0xa3, 0x0f, 0x61, 0xbb, 0x76, 0x63, 0xc7, 0xf9, 0x76, 0xea, 0x26, 0x59, 0x3b, 0x93, 0xfc,
+	0x33, 0x80, 0xe9, 0x6b, 0x2e, 0x4a, 0x8d, 0x25, 0xff, 0x7f, 0xfc, 0xf8, 0x32, 0xca, 0x89, 0xda,
+	0x4b, 0x54, 0x2b, 0x97, 0xb1, 0x16, 0x90, 0xb4, 0xa1, 0xab, 0xc1, 0xe5, 0xaa, 0x05, 0x7d, 0xef,
+	0xc7, 0xef, 0xf4, 0x7e, 0x7b, 0xdd, 0xfb, 0xa3, 0xee, 0xc2, 0xe9, 0xbb, 0x92, 0xc2, 0xb4, 0xe8,
+	0xa0, 0xe3, 0x60, 0x27, 0xed, 0xa9, 0xb0, 0xbe, 0x42, 0xf2, 0xf7, 0x10, 0x46, 0xbf, 0xb5, 0x49,
+	0xb8, 0xf1, 0xb6, 0x2b, 0x50, 0x9f, 0xcb, 0xcc, 0x51, 0xe1, 0x10, 0xdd, 0x20, 0x67, 0x32, 0x5b,
+	0x19, 0x32, 0x26, 0xcc, 0x8c, 0x7b, 0xcd, 0xd2, 0xd6, 0x41, 0x48, 0xba, 0x16, 0x45, 0xf7, 0x61,
+	0x34, 0xa7, 0x9e, 0x88, 0x2e, 0x6d, 0xb2, 0x65, 0x94, 0x9a, 0x16, 0x89, 0x39, 0x69, 0x94, 0xc2,
+	0xf8, 0x1c, 0x79, 0x86, 0xaa, 0x8e, 0x47, 0x46, 0x61, 0x3f, 0xb5, 0xf6, 0xa4, 0x2f, 0xad, 0xf8,
+	0xa8, 0xd4, 0x6a, 0xc5, 0xbc, 0x12, 0xed, 0xad, 0xf9, 0xb2, 0x8e, 0xc7, 0x66, 0x17, 0x33, 0xa6,
+	0x73, 0x20, 0x8f, 0xd4, 0x65, 0x7b, 0xad, 0xb7, 0x98, 0x18, 0xa4, 0x62, 0x27, 0x1b, 0x6d, 0xba,
+	0xa3, 0x90, 0x79, 0x18, 0xbd, 0x0f, 0x43, 0xea, 0x86, 0x56, 0xa6, 0x35, 0x22, 0xc3, 0x18, 0x21,
+	0x66, 0x85, 0xb4, 0x4f, 0x4d, 0x41, 0x3a, 0xb5, 0x3e, 0xd2, 0x98, 0x7c, 0xc4, 0xab, 0x8a, 0xda,
+	0x92, 0x1d, 0xcb, 0x87, 0x45, 0x36, 0xbd, 0xe6, 0x52, 0x65, 0xf1, 0xae, 0x4f, 0x2f, 0x42, 0x24,
+	0xbf, 0xe4, 0x79, 0x83, 0x75, 0xbc, 0x67, 0x39, 0xb1, 0x68, 0xf6, 0x29, 0xec, 0xf4, 0x9d, 0x23,
+	0xe6, 0x2f, 0x70, 0xe5, 0x99, 0xbf, 0x40, 0x13, 0x27, 0x46, 0xd7, 0x11, 0x6f, 0xc1, 0xa7, 0x83,
+	0x4f, 0x82, 0xe4, 0x35, 0x0c, 0x8d, 0x9d, 0x6b, 0x7d, 0x5f, 0x70, 0xb3, 0xef, 0x3b, 0xe3, 0xf3,
+	0x0b, 0xb9, 0x58, 0xf8, 0x0b, 0xd6, 0x41, 0x0a, 0x75, 0x59, 0xc6, 0xa1, 0x31, 0x67, 0x20, 0xcb,
+	0xe4, 0x08, 0x86, 0xb6, 0x65, 0x25, 0x5e, 0x57, 0x15, 0x3a, 0x23, 0xcc, 0x98, 0x64, 0x15, 0xd7,
+	0xe7, 0xce, 0x08, 0x33, 0xee, 0x2c, 0x0b, 0x7b, 0x96, 0x25, 0x1f, 0xc0, 0xae, 0x2b, 0x19, 0xef,
+	0x0a, 0xa6, 0xe4, 0x67, 0x5d, 0xc7, 0x69, 0x75, 0xea, 0xe8, 0x03, 0x18, 0xdb, 0x0b, 0xc0, 0x27,
+	0xeb, 0xd8, 0x9d, 0x3d, 0xf3, 0x72, 0x6a, 0x29, 0xba, 0xc6, 0x4a, 0xe6, 0x39, 0xaa, 0x53, 0xcd,
+	0x75, 0x4d, 0x0d, 0x46, 0x3d, 0x3f, 0xc7, 0xac, 0xc9, 0xd1, 0x67, 0x6e, 0x27, 0x20, 0xff, 0xeb,
+	0x0b, 0x51, 0x55, 0x68, 0x23, 0x77, 0x8b, 0x79, 0x48, 0x47, 0xf2, 0x45, 0x83, 0x0d, 0xda, 0xd2,
+	0xb9, 0xc5, 0x1c, 0xb2, 0xef, 0x16, 0x6d, 0x6f, 0xde, 0x2d, 0x66, 0xc6, 0x36, 0xac, 0x7e, 0x9d,
+	0x8b, 0xe5, 0xb9, 0x36, 0x59, 0x3c, 0x64, 0x2d, 0x4e, 0xfe, 0xd1, 0xb3, 0xeb, 0x39, 0x2a, 0x2d,
+	0x16, 0x62, 0x4e, 0xdf, 0x7c, 0xd3, 0xe6, 0x37, 0x81, 0xe1, 0xfc, 0x9c, 0x0b, 0x7b, 0x24, 0x94,
+	0xa9, 0xbd, 0xc5, 0x98, 0x9d, 0xea, 0xaa, 0xf4, 0xd6, 0xb5, 0x57, 0xc3, 0x5b, 0xae, 0x4a, 0x51,
+	0x2e, 0x6d, 0x6a, 0x4d, 0x58, 0x8b, 0xc9, 0x7f, 0xbc, 0xaa, 0x04, 0x35, 0x19, 0xb6, 0xd0, 0x78,
+	0x98, 0xfc, 0x2b, 0x80, 0x69, 0xdf, 0x5e, 0x62, 0xaa, 0x39, 0xfb, 0x03, 0xc5, 0xb4, 0xb5, 0xd9,
+	0x43, 0x62, 0x4a, 0xd4, 0x75, 0x83, 0xca, 0x27, 0xbf, 0x45, 0xa6, 0xe8, 0x95, 0xf5, 0x6f, 0x78,
+	0x61, 0x3a, 0x18, 0xb3, 0xaf, 0xc7, 0xe4, 0x6b, 0x29, 0xf5, 0x33, 0x5c, 0x48, 0x85, 0xae, 0x22,
+	0x76, 0x02, 0xfa, 0xb2, 0x94, 0xfa, 0x70, 0xa1, 0x51, 0xf9, 0x3e, 0xc6, 0xe3, 0x28, 0x85, 0xa8,
+	0x16, 0xcb, 0x92, 0xeb, 0x46, 0xe1, 0x61, 0xbe, 0x94, 0x4a, 0xe8, 0xf3, 0xc2, 0x55, 0xfc, 0x0d,
+	0x33, 0x8f, 0xff, 0x3b, 0x06, 0x78, 0x2d, 0x4b, 0xa1, 0xa5, 0xa2, 0x67, 0xd2, 0x47, 0x00, 0xc7,
+	0x48, 0x4f, 0x47, 0xf3, 0xf4, 0x9b, 0xa6, 0xdd, 0x5b, 0x72, 0xb6, 0x9b, 0xf6, 0x9f, 0x85, 0xc9,
+	0x77, 0xa2, 0x8f, 0x60, 0xf7, 0x18, 0xf5, 0x6b, 0x7e, 0xe5, 0x1f, 0x3e, 0xfe, 0x3e, 0x9f, 0xdd,
+	0x4e, 0xaf, 0xbf, 0x89, 0x9c, 0xae, 0x28, 0xbf, 0x86, 0xee, 0xc7, 0x70, 0xf7, 0x18, 0xb5, 0x43,
+	0xdd, 0x73, 0x68, 0xcd, 0x98, 0x28, 0xbd, 0xf9, 0x5e, 0x7a, 0x06, 0xef, 0x75, 0xdf, 0xf5, 0x1f,
+	0x0f, 0x77, 0xd3, 0x9b, 0x6f, 0x92, 0xd9, 0x7e, 0xba, 0xe9, 0x9d, 0xf1, 0xc0, 0xf8, 0xef, 0x7b,
+	0xfe, 0x5b, 0xe9, 0xfa, 0x23, 0x60, 0x36, 0x4a, 0x4d, 0x5b, 0xfe, 0x28, 0x88, 0x3e, 0x83, 0x5b,
+	0xc7, 0xb8, 0xde, 0x06, 0xef, 0xa7, 0x1b, 0x9a, 0xe3, 0xd9, 0xe6, 0x8e, 0x2a, 0xfa, 0x11, 0xec,
+	0x1c, 0x23, 0xf5, 0x84, 0x0c, 0x2b, 0xa9, 0x74, 0xe7, 0xdd, 0xe9, 0xc9, 0xe1, 0x6c, 0x27, 0xed,
+	0x37, 0x7b, 0x1f, 0xc3, 0xee, 0x89, 0xe8, 0x77, 0x56, 0x77, 0xd2, 0xeb, 0xcd, 0x56, 0x8f, 0x8f,
+	0x4e, 0xed, 0x00, 0xe0, 0x30, 0xcb, 0x7c, 0x8f, 0xd1, 0xde, 0xdf, 0xb3, 0x76, 0x44, 0x46, 0xd0,
+	0xca, 0xed, 0xad, 0x3f, 0x4a, 0xcd, 0xdf, 0x10, 0xb3, 0x3b, 0xe9, 0x8d, 0x86, 0xe0, 0x07, 0xb0,
+	0xfb, 0x02, 0x73, 0xd4, 0x5e, 0x12, 0x41, 0x6b, 0xc4, 0x8b, 0x99, 0xfb, 0x2e, 0xfa, 0x31, 0xec,
+	0x1d, 0x66, 0x59, 0xff, 0x1a, 0x5d, 0xbb, 0x31, 0x67, 0x6b, 0x28, 0xfa, 0x09, 0xdc, 0xa2, 0xdd,
+	0xfb, 0x22, 0x6f, 0x40, 0x77, 0x38, 0xfd, 0xd9, 0x07, 0x70, 0xc7, 0xda, 0xd0, 0x17, 0x6e, 0xb2,
+	0xe3, 0x7b, 0x30, 0x39, 0xcc, 0x32, 0x57, 0x41, 0x7d, 0x2d, 0x9c, 0xf9, 0x41, 0x74, 0x00, 0x3b,
+	0x6f, 0xaa, 0x8c, 0x6b, 0x7c, 0xa7, 0xc6, 0x0f, 0xe9, 0x3f, 0x90, 0x42, 0x5e, 0x7a, 0x8d, 0xbd,
+	0x74, 0xad, 0x2a, 0xb7, 0x1b, 0x3d, 0x80, 0xe9, 0x89, 0xf0, 0xd2, 0x8e, 0xbf, 0x2e, 0xa6, 0xfd,
+	0xcc, 0x87, 0x30, 0x39, 0x46, 0xfd, 0x8e, 0xd5, 0xda, 0x6d, 0x53, 0xd8, 0x3b, 0x46, 0xdd, 0x2f,
+	0xcf, 0x37, 0x09, 0xe9, 0xcf, 0xfe, 0x1c, 0xa2, 0x63, 0xd4, 0xbd, 0x32, 0x74, 0x33, 0x6b, 0xbb,
+	0x0f, 0x7b, 0x6a, 0x67, 0x23, 0xf3, 0xbf, 0xd3, 0x4f, 0xff, 0x37, 0x00, 0x9f, 0x63, 0x4e, 0xb1,
+	0x85, 0x12, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// MonitoringClient is the client API for Monitoring service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MonitoringClient interface {
+	GetURLInfo(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseInfo, error)
+	GetMaxLatency(ctx context.Context, in *Window, opts ...grpc.CallOption) (*ResponseLatency, error)
+	GetMinLatency(ctx context.Context, in *Window, opts ...grpc.CallOption) (*ResponseLatency, error)
+	GetLatencyBreakdown(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseBreakdown, error)
+	GetLatencyPercentiles(ctx context.Context, in *RequestPercentiles, opts ...grpc.CallOption) (*ResponsePercentiles, error)
+	GetHistory(ctx context.Context, in *RequestHistory, opts ...grpc.CallOption) (Monitoring_GetHistoryClient, error)
+	GetAvailability(ctx context.Context, in *RequestAvailability, opts ...grpc.CallOption) (*ResponseAvailability, error)
+	GetSLAReport(ctx context.Context, in *RequestSLA, opts ...grpc.CallOption) (*ResponseSLA, error)
+	ListIncidents(ctx context.Context, in *RequestIncidents, opts ...grpc.CallOption) (*ResponseIncidents, error)
+	AddSilence(ctx context.Context, in *Silence, opts ...grpc.CallOption) (*Silence, error)
+	ListSilences(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseSilences, error)
+	DeleteSilence(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*Empty, error)
+	AddMaintenance(ctx context.Context, in *Maintenance, opts ...grpc.CallOption) (*Maintenance, error)
+	ListMaintenance(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseMaintenance, error)
+	DeleteMaintenance(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*Empty, error)
+	AddTarget(ctx context.Context, in *Target, opts ...grpc.CallOption) (*Target, error)
+	UpdateTarget(ctx context.Context, in *Target, opts ...grpc.CallOption) (*Target, error)
+	RemoveTarget(ctx context.Context, in *RequestTarget, opts ...grpc.CallOption) (*Empty, error)
+	ListTargets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseTargets, error)
+	GetTarget(ctx context.Context, in *RequestTarget, opts ...grpc.CallOption) (*Target, error)
+	GetPollerStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponsePollerStats, error)
+	GetCertificateInfo(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseCertificate, error)
+}
+
+type monitoringClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMonitoringClient(cc *grpc.ClientConn) MonitoringClient {
+	return &monitoringClient{cc}
+}
+
+func (c *monitoringClient) GetURLInfo(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseInfo, error) {
+	out := new(ResponseInfo)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetURLInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetMaxLatency(ctx context.Context, in *Window, opts ...grpc.CallOption) (*ResponseLatency, error) {
+	out := new(ResponseLatency)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetMaxLatency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetMinLatency(ctx context.Context, in *Window, opts ...grpc.CallOption) (*ResponseLatency, error) {
+	out := new(ResponseLatency)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetMinLatency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetLatencyBreakdown(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseBreakdown, error) {
+	out := new(ResponseBreakdown)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetLatencyBreakdown", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetLatencyPercentiles(ctx context.Context, in *RequestPercentiles, opts ...grpc.CallOption) (*ResponsePercentiles, error) {
+	out := new(ResponsePercentiles)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetLatencyPercentiles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetHistory(ctx context.Context, in *RequestHistory, opts ...grpc.CallOption) (Monitoring_GetHistoryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Monitoring_serviceDesc.Streams[0], "/Monitoring/GetHistory", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &monitoringGetHistoryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Monitoring_GetHistoryClient interface {
+	Recv() (*Point, error)
+	grpc.ClientStream
+}
+
+type monitoringGetHistoryClient struct {
+	grpc.ClientStream
+}
+
+func (x *monitoringGetHistoryClient) Recv() (*Point, error) {
+	m := new(Point)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *monitoringClient) GetAvailability(ctx context.Context, in *RequestAvailability, opts ...grpc.CallOption) (*ResponseAvailability, error) {
+	out := new(ResponseAvailability)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetAvailability", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetSLAReport(ctx context.Context, in *RequestSLA, opts ...grpc.CallOption) (*ResponseSLA, error) {
+	out := new(ResponseSLA)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetSLAReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) ListIncidents(ctx context.Context, in *RequestIncidents, opts ...grpc.CallOption) (*ResponseIncidents, error) {
+	out := new(ResponseIncidents)
+	err := c.cc.Invoke(ctx, "/Monitoring/ListIncidents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) AddSilence(ctx context.Context, in *Silence, opts ...grpc.CallOption) (*Silence, error) {
+	out := new(Silence)
+	err := c.cc.Invoke(ctx, "/Monitoring/AddSilence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) ListSilences(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseSilences, error) {
+	out := new(ResponseSilences)
+	err := c.cc.Invoke(ctx, "/Monitoring/ListSilences", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) DeleteSilence(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Monitoring/DeleteSilence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) AddMaintenance(ctx context.Context, in *Maintenance, opts ...grpc.CallOption) (*Maintenance, error) {
+	out := new(Maintenance)
+	err := c.cc.Invoke(ctx, "/Monitoring/AddMaintenance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) ListMaintenance(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseMaintenance, error) {
+	out := new(ResponseMaintenance)
+	err := c.cc.Invoke(ctx, "/Monitoring/ListMaintenance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) DeleteMaintenance(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Monitoring/DeleteMaintenance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) AddTarget(ctx context.Context, in *Target, opts ...grpc.CallOption) (*Target, error) {
+	out := new(Target)
+	err := c.cc.Invoke(ctx, "/Monitoring/AddTarget", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) UpdateTarget(ctx context.Context, in *Target, opts ...grpc.CallOption) (*Target, error) {
+	out := new(Target)
+	err := c.cc.Invoke(ctx, "/Monitoring/UpdateTarget", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) RemoveTarget(ctx context.Context, in *RequestTarget, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Monitoring/RemoveTarget", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) ListTargets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponseTargets, error) {
+	out := new(ResponseTargets)
+	err := c.cc.Invoke(ctx, "/Monitoring/ListTargets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetTarget(ctx context.Context, in *RequestTarget, opts ...grpc.CallOption) (*Target, error) {
+	out := new(Target)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetTarget", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetPollerStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResponsePollerStats, error) {
+	out := new(ResponsePollerStats)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetPollerStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringClient) GetCertificateInfo(ctx context.Context, in *RequestURL, opts ...grpc.CallOption) (*ResponseCertificate, error) {
+	out := new(ResponseCertificate)
+	err := c.cc.Invoke(ctx, "/Monitoring/GetCertificateInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MonitoringServer is the server API for Monitoring service.
+type MonitoringServer interface {
+	GetURLInfo(context.Context, *RequestURL) (*ResponseInfo, error)
+	GetMaxLatency(context.Context, *Window) (*ResponseLatency, error)
+	GetMinLatency(context.Context, *Window) (*ResponseLatency, error)
+	GetLatencyBreakdown(context.Context, *RequestURL) (*ResponseBreakdown, error)
+	GetLatencyPercentiles(context.Context, *RequestPercentiles) (*ResponsePercentiles, error)
+	GetHistory(*RequestHistory, Monitoring_GetHistoryServer) error
+	GetAvailability(context.Context, *RequestAvailability) (*ResponseAvailability, error)
+	GetSLAReport(context.Context, *RequestSLA) (*ResponseSLA, error)
+	ListIncidents(context.Context, *RequestIncidents) (*ResponseIncidents, error)
+	AddSilence(context.Context, *Silence) (*Silence, error)
+	ListSilences(context.Context, *Empty) (*ResponseSilences, error)
+	DeleteSilence(context.Context, *RequestID) (*Empty, error)
+	AddMaintenance(context.Context, *Maintenance) (*Maintenance, error)
+	ListMaintenance(context.Context, *Empty) (*ResponseMaintenance, error)
+	DeleteMaintenance(context.Context, *RequestID) (*Empty, error)
+	AddTarget(context.Context, *Target) (*Target, error)
+	UpdateTarget(context.Context, *Target) (*Target, error)
+	RemoveTarget(context.Context, *RequestTarget) (*Empty, error)
+	ListTargets(context.Context, *Empty) (*ResponseTargets, error)
+	GetTarget(context.Context, *RequestTarget) (*Target, error)
+	GetPollerStats(context.Context, *Empty) (*ResponsePollerStats, error)
+	GetCertificateInfo(context.Context, *RequestURL) (*ResponseCertificate, error)
+}
+
+func RegisterMonitoringServer(s *grpc.Server, srv MonitoringServer) {
+	s.RegisterService(&_Monitoring_serviceDesc, srv)
+}
+
+func _Monitoring_GetURLInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetURLInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetURLInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetURLInfo(ctx, req.(*RequestURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetMaxLatency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Window)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetMaxLatency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetMaxLatency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetMaxLatency(ctx, req.(*Window))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetMinLatency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Window)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetMinLatency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetMinLatency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetMinLatency(ctx, req.(*Window))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetLatencyBreakdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetLatencyBreakdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetLatencyBreakdown",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetLatencyBreakdown(ctx, req.(*RequestURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetLatencyPercentiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPercentiles)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetLatencyPercentiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetLatencyPercentiles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetLatencyPercentiles(ctx, req.(*RequestPercentiles))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetHistory_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RequestHistory)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MonitoringServer).GetHistory(m, &monitoringGetHistoryServer{stream})
+}
+
+type Monitoring_GetHistoryServer interface {
+	Send(*Point) error
+	grpc.ServerStream
+}
+
+type monitoringGetHistoryServer struct {
+	grpc.ServerStream
+}
+
+func (x *monitoringGetHistoryServer) Send(m *Point) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Monitoring_GetAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestAvailability)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetAvailability",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetAvailability(ctx, req.(*RequestAvailability))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetSLAReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestSLA)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetSLAReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetSLAReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetSLAReport(ctx, req.(*RequestSLA))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_ListIncidents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestIncidents)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).ListIncidents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/ListIncidents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).ListIncidents(ctx, req.(*RequestIncidents))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_AddSilence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Silence)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).AddSilence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/AddSilence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).AddSilence(ctx, req.(*Silence))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_ListSilences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).ListSilences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/ListSilences",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).ListSilences(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_DeleteSilence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).DeleteSilence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/DeleteSilence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).DeleteSilence(ctx, req.(*RequestID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_AddMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Maintenance)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).AddMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/AddMaintenance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).AddMaintenance(ctx, req.(*Maintenance))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_ListMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).ListMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/ListMaintenance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).ListMaintenance(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_DeleteMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).DeleteMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/DeleteMaintenance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).DeleteMaintenance(ctx, req.(*RequestID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_AddTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Target)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).AddTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/AddTarget",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).AddTarget(ctx, req.(*Target))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_UpdateTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Target)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).UpdateTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/UpdateTarget",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).UpdateTarget(ctx, req.(*Target))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_RemoveTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestTarget)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).RemoveTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/RemoveTarget",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).RemoveTarget(ctx, req.(*RequestTarget))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_ListTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).ListTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/ListTargets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).ListTargets(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestTarget)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetTarget",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetTarget(ctx, req.(*RequestTarget))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetPollerStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetPollerStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetPollerStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetPollerStats(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitoring_GetCertificateInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServer).GetCertificateInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Monitoring/GetCertificateInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServer).GetCertificateInfo(ctx, req.(*RequestURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Monitoring_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "Monitoring",
+	HandlerType: (*MonitoringServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetURLInfo",
+			Handler:    _Monitoring_GetURLInfo_Handler,
+		},
 		{
 			MethodName: "GetMaxLatency",
 			Handler:    _Monitoring_GetMaxLatency_Handler,
@@ -346,7 +2870,85 @@ var _Monitoring_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetMinLatency",
 			Handler:    _Monitoring_GetMinLatency_Handler,
 		},
+		{
+			MethodName: "GetLatencyBreakdown",
+			Handler:    _Monitoring_GetLatencyBreakdown_Handler,
+		},
+		{
+			MethodName: "GetLatencyPercentiles",
+			Handler:    _Monitoring_GetLatencyPercentiles_Handler,
+		},
+		{
+			MethodName: "GetAvailability",
+			Handler:    _Monitoring_GetAvailability_Handler,
+		},
+		{
+			MethodName: "GetSLAReport",
+			Handler:    _Monitoring_GetSLAReport_Handler,
+		},
+		{
+			MethodName: "ListIncidents",
+			Handler:    _Monitoring_ListIncidents_Handler,
+		},
+		{
+			MethodName: "AddSilence",
+			Handler:    _Monitoring_AddSilence_Handler,
+		},
+		{
+			MethodName: "ListSilences",
+			Handler:    _Monitoring_ListSilences_Handler,
+		},
+		{
+			MethodName: "DeleteSilence",
+			Handler:    _Monitoring_DeleteSilence_Handler,
+		},
+		{
+			MethodName: "AddMaintenance",
+			Handler:    _Monitoring_AddMaintenance_Handler,
+		},
+		{
+			MethodName: "ListMaintenance",
+			Handler:    _Monitoring_ListMaintenance_Handler,
+		},
+		{
+			MethodName: "DeleteMaintenance",
+			Handler:    _Monitoring_DeleteMaintenance_Handler,
+		},
+		{
+			MethodName: "AddTarget",
+			Handler:    _Monitoring_AddTarget_Handler,
+		},
+		{
+			MethodName: "UpdateTarget",
+			Handler:    _Monitoring_UpdateTarget_Handler,
+		},
+		{
+			MethodName: "RemoveTarget",
+			Handler:    _Monitoring_RemoveTarget_Handler,
+		},
+		{
+			MethodName: "ListTargets",
+			Handler:    _Monitoring_ListTargets_Handler,
+		},
+		{
+			MethodName: "GetTarget",
+			Handler:    _Monitoring_GetTarget_Handler,
+		},
+		{
+			MethodName: "GetPollerStats",
+			Handler:    _Monitoring_GetPollerStats_Handler,
+		},
+		{
+			MethodName: "GetCertificateInfo",
+			Handler:    _Monitoring_GetCertificateInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetHistory",
+			Handler:       _Monitoring_GetHistory_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc.proto",
 }
@@ -0,0 +1,82 @@
+package poller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// defaultCertWarnDays - за сколько дней до окончания действия сертификата
+// предупреждать, если Poller.CertWarnDays не задан.
+const defaultCertWarnDays = 14
+
+// certInfo возвращает сведения о цепочке certs, полученной в момент now,
+// с предупреждениями о скором окончании действия и ошибке проверки verr.
+func (p *Poller) certInfo(certs []*x509.Certificate, verr error, now time.Time) *storage.CertInfo {
+	info := &storage.CertInfo{Time: now}
+	for _, cert := range certs {
+		info.Chain = append(info.Chain, storage.Certificate{
+			Subject:            cert.Subject.String(),
+			Issuer:             cert.Issuer.String(),
+			DNSNames:           cert.DNSNames,
+			NotBefore:          cert.NotBefore,
+			NotAfter:           cert.NotAfter,
+			SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		})
+	}
+
+	if verr != nil {
+		info.Error = verr.Error()
+		info.Warnings = append(info.Warnings, "invalid certificate: "+verr.Error())
+	}
+	days := p.CertWarnDays
+	if days == 0 {
+		days = defaultCertWarnDays
+	}
+	if expires := info.Expires(); !expires.IsZero() && days > 0 {
+		if left := expires.Sub(now); left < time.Duration(days)*24*time.Hour {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("certificate expires in %d days on %s",
+				int(left.Hours()/24), expires.UTC().Format("2006-01-02")))
+		}
+	}
+	return info
+}
+
+// inspectTLS подключается к ресурсу u без проверки сертификата, чтобы получить
+// цепочку, которая не прошла проверку при запросе, и проверяет ее отдельно.
+// Возвращает nil, если подключиться не удалось.
+func (p *Poller) inspectTLS(ctx context.Context, u *url.URL) *storage.CertInfo {
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "443")
+	}
+	d := tls.Dialer{Config: &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: true}}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	opts := x509.VerifyOptions{
+		DNSName:       u.Hostname(),
+		Roots:         p.RootCAs,
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   now,
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err = certs[0].Verify(opts)
+	return p.certInfo(certs, err, now)
+}
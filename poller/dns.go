@@ -0,0 +1,219 @@
+package poller
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+var recordTypes = map[string]dnsmessage.Type{
+	RecordA:     dnsmessage.TypeA,
+	RecordAAAA:  dnsmessage.TypeAAAA,
+	RecordCNAME: dnsmessage.TypeCNAME,
+	RecordMX:    dnsmessage.TypeMX,
+	RecordTXT:   dnsmessage.TypeTXT,
+}
+
+// maxDNSSize - размер буфера для ответа резолвера. Ответ, не поместившийся
+// в udp пакет, запрашивается повторно по tcp.
+const maxDNSSize = 65535
+
+// probeDNS запрашивает у резолвера из url записи типа t.Record и проверяет,
+// что в ответе есть все значения t.Values. Время отклика - время получения
+// ответа резолвера. При несовпадении значений полученный ответ сохраняется
+// в описании ошибки.
+func (p *Poller) probeDNS(ctx context.Context, t *Target) *call {
+	c := new(call)
+	c.url = t.URL
+	c.time = time.Now()
+
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		c.fail(storage.ReasonUnknown, err)
+		return c
+	}
+	server := u.Host
+	if u.Port() == "" {
+		server = net.JoinHostPort(u.Hostname(), "53")
+	}
+	name, err := dnsmessage.NewName(dnsName(strings.Trim(u.Path, "/")))
+	if err != nil {
+		c.fail(storage.ReasonUnknown, err)
+		return c
+	}
+	q := dnsmessage.Question{Name: name, Type: recordTypes[t.Record], Class: dnsmessage.ClassINET}
+
+	reqCtx := ctx
+	if timeout := t.timeout(p.Timeout); timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(reqCtx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	msg, err := exchange(ctx, reqCtx, "udp", server, q)
+	if err == nil && msg.Truncated {
+		msg, err = exchange(ctx, reqCtx, "tcp", server, q)
+	}
+	elapsed := time.Since(start)
+	if err != nil {
+		c.canceled = ctx.Err() != nil
+		log.Printf("[WARN] dns error: %v", err)
+		c.fail(classifyError(err), err)
+		return c
+	}
+	c.latency = elapsed.Nanoseconds()
+	c.phases = &storage.Phases{DNS: c.latency}
+
+	if msg.RCode != dnsmessage.RCodeSuccess {
+		c.fail(storage.ReasonDNS, fmt.Errorf("%s %s: %v", q.Name, t.Record, msg.RCode))
+		return c
+	}
+	answer := answerValues(msg, q.Type)
+	if len(answer) == 0 {
+		c.fail(storage.ReasonDNS, fmt.Errorf("%s %s: no records", q.Name, t.Record))
+		return c
+	}
+	if missing := missingValues(t.Record, t.Values, answer); len(missing) > 0 {
+		c.fail(storage.ReasonBodyMismatch, fmt.Errorf("observed %s", strings.Join(answer, ", ")))
+		c.check = fmt.Sprintf("%s %s", t.Record, strings.Join(missing, ", "))
+		return c
+	}
+	c.isAvailable = true
+	return c
+}
+
+// dnsName возвращает полное имя с точкой на конце.
+func dnsName(name string) string {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// exchange отправляет запрос q резолверу server по сети network (udp или tcp)
+// и возвращает ответ.
+func exchange(ctx, reqCtx context.Context, network, server string, q dnsmessage.Question) (*dnsmessage.Message, error) {
+	id := uint16(rand.Uint32())
+	b := dnsmessage.NewBuilder(make([]byte, 2, 514), dnsmessage.Header{ID: id, RecursionDesired: true})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	req, err := b.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(reqCtx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer bindConn(ctx, reqCtx, conn)()
+
+	// по tcp сообщение передается с двухбайтовым префиксом длины
+	buf := make([]byte, maxDNSSize+2)
+	var resp []byte
+	if network == "tcp" {
+		binary.BigEndian.PutUint16(req, uint16(len(req)-2))
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+			return nil, err
+		}
+		n := int(binary.BigEndian.Uint16(buf))
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return nil, err
+		}
+		resp = buf[:n]
+	} else {
+		if _, err := conn.Write(req[2:]); err != nil {
+			return nil, err
+		}
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		resp = buf[:n]
+	}
+
+	msg := new(dnsmessage.Message)
+	if err := msg.Unpack(resp); err != nil {
+		return nil, err
+	}
+	if msg.ID != id || !msg.Response {
+		return nil, errors.New("dns: unexpected response id")
+	}
+	return msg, nil
+}
+
+// answerValues возвращает отсортированные значения записей типа typ из ответа.
+// Имена возвращаются без точки на конце, части TXT записи объединяются.
+func answerValues(msg *dnsmessage.Message, typ dnsmessage.Type) []string {
+	var values []string
+	for _, r := range msg.Answers {
+		if r.Header.Type != typ {
+			continue // например, CNAME перед A записями
+		}
+		switch b := r.Body.(type) {
+		case *dnsmessage.AResource:
+			values = append(values, net.IP(b.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			values = append(values, net.IP(b.AAAA[:]).String())
+		case *dnsmessage.CNAMEResource:
+			values = append(values, strings.TrimSuffix(b.CNAME.String(), "."))
+		case *dnsmessage.MXResource:
+			values = append(values, strings.TrimSuffix(b.MX.String(), "."))
+		case *dnsmessage.TXTResource:
+			values = append(values, strings.Join(b.TXT, ""))
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// missingValues возвращает ожидаемые значения, которых нет в ответе.
+// Имена сравниваются без учета регистра и точки на конце, ip - в
+// каноническом виде.
+func missingValues(record string, expected, answer []string) []string {
+	normalize := func(v string) string {
+		switch record {
+		case RecordA, RecordAAAA:
+			if ip := net.ParseIP(v); ip != nil {
+				return ip.String()
+			}
+		case RecordCNAME, RecordMX:
+			return strings.ToLower(strings.TrimSuffix(v, "."))
+		}
+		return v
+	}
+	got := make(map[string]bool)
+	for _, v := range answer {
+		got[normalize(v)] = true
+	}
+	var missing []string
+	for _, v := range expected {
+		if !got[normalize(v)] {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,48 @@
+package poller
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// classifyError определяет причину недоступности ресурса по ошибке запроса.
+func classifyError(err error) string {
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return storage.ReasonTimeout
+	}
+
+	for err != nil {
+		switch e := err.(type) {
+		case *url.Error:
+			err = e.Err
+		case *net.DNSError:
+			return storage.ReasonDNS
+		case *net.OpError:
+			if e.Op == "dial" {
+				if _, ok := e.Err.(*net.DNSError); ok {
+					return storage.ReasonDNS
+				}
+				return storage.ReasonConnect
+			}
+			err = e.Err
+		case *os.SyscallError:
+			return storage.ReasonConnect
+		case tls.RecordHeaderError, x509.UnknownAuthorityError, x509.HostnameError,
+			x509.CertificateInvalidError, x509.SystemRootsError:
+			return storage.ReasonTLS
+		default:
+			// ошибки tls alert не экспортируются пакетом crypto/tls
+			if strings.Contains(err.Error(), "tls:") || strings.Contains(err.Error(), "x509:") {
+				return storage.ReasonTLS
+			}
+			return storage.ReasonUnknown
+		}
+	}
+	return storage.ReasonUnknown
+}
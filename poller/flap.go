@@ -0,0 +1,102 @@
+package poller
+
+import (
+	"log"
+	"sync"
+)
+
+// Пороги процента смены состояния по умолчанию
+const (
+	defaultFlapLow  = 25
+	defaultFlapHigh = 50
+)
+
+// flapHistory - число последних состояний ресурса, по которым считается
+// процент смены состояния.
+const flapHistory = 21
+
+// flapDetector определяет нестабильные ресурсы по аналогии с Nagios: по
+// последним flapHistory состояниям считается взвешенный процент смены
+// состояния, где более поздние смены весят больше (от 0.8 до 1.2).
+// Ресурс считается нестабильным, когда процент достигает high, и снова
+// стабильным, когда процент опускается ниже low.
+type flapDetector struct {
+	low  float64
+	high float64
+
+	mu     sync.Mutex
+	states map[string]*flapState
+}
+
+type flapState struct {
+	history  []bool // состояния доступности, от старых к новым
+	flapping bool
+}
+
+func newFlapDetector(low, high float64) *flapDetector {
+	if low <= 0 {
+		low = defaultFlapLow
+	}
+	if high <= 0 {
+		high = defaultFlapHigh
+	}
+	return &flapDetector{low: low, high: high, states: make(map[string]*flapState)}
+}
+
+// track учитывает результат проверки и возвращает, нестабилен ли ресурс,
+// и процент смены состояния.
+func (d *flapDetector) track(c *call) (bool, float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st, ok := d.states[c.url]
+	if !ok {
+		st = new(flapState)
+		d.states[c.url] = st
+	}
+	st.history = append(st.history, c.isAvailable)
+	if len(st.history) > flapHistory {
+		st.history = st.history[1:]
+	}
+
+	pct := stateChange(st.history)
+	switch {
+	case !st.flapping && pct >= d.high:
+		st.flapping = true
+		log.Printf("[WARN] Flapping started: %s %.1f%%", c.url, pct)
+	case st.flapping && pct < d.low:
+		st.flapping = false
+		log.Printf("[INFO] Flapping stopped: %s %.1f%%", c.url, pct)
+	}
+	return st.flapping, pct
+}
+
+// retain удаляет состояние ресурсов, для которых keep возвращает false.
+func (d *flapDetector) retain(keep func(url string) bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for url := range d.states {
+		if !keep(url) {
+			delete(d.states, url)
+		}
+	}
+}
+
+// stateChange возвращает взвешенный процент смены состояния. Пока история
+// неполная, недостающие состояния считаются неизменными.
+func stateChange(history []bool) float64 {
+	const (
+		lowWeight  = 0.8
+		highWeight = 1.2
+		steps      = flapHistory - 1
+	)
+	var changes float64
+	offset := flapHistory - len(history) // смены в начале истории считаются старыми
+	for i := 1; i < len(history); i++ {
+		if history[i] != history[i-1] {
+			step := offset + i - 1
+			changes += lowWeight + (highWeight-lowWeight)*float64(step)/float64(steps-1)
+		}
+	}
+	return changes / steps * 100
+}
@@ -0,0 +1,106 @@
+package poller
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// probeGRPC подключается к ресурсу и вызывает grpc.health.v1.Health/Check
+// для сервиса из пути url (пустой - сервер в целом). Ресурс доступен, если
+// сервис в статусе SERVING. Для grpcs:// подключение выполняется по tls,
+// сертификаты сохраняются как у https ресурсов. Время отклика включает
+// установку соединения, т.к. соединение создается на каждую проверку.
+// Статус сервиса, отличный от SERVING, сохраняется в описании ошибки.
+func (p *Poller) probeGRPC(ctx context.Context, t *Target) *call {
+	c := new(call)
+	c.url = t.URL
+	c.time = time.Now()
+
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		c.fail(classifyError(err), err)
+		return c
+	}
+	creds := insecure.NewCredentials()
+	if u.Scheme == "grpcs" {
+		creds = credentials.NewTLS(&tls.Config{ServerName: u.Hostname(), RootCAs: p.RootCAs})
+	}
+
+	// соединение устанавливается при вызове, поэтому таймаут вызова
+	// ограничивает и подключение
+	conn, err := grpc.NewClient(u.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		log.Printf("[WARN] grpc error: %v", err)
+		c.fail(classifyError(err), err)
+		return c
+	}
+	defer conn.Close()
+
+	reqCtx := ctx
+	if timeout := t.timeout(p.Timeout); timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(reqCtx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+
+	var pr peer.Peer
+	resp, err := healthpb.NewHealthClient(conn).Check(reqCtx,
+		&healthpb.HealthCheckRequest{Service: strings.Trim(u.Path, "/")}, grpc.Peer(&pr))
+	elapsed := time.Since(start)
+	if err != nil {
+		c.canceled = ctx.Err() != nil
+		log.Printf("[WARN] grpc error: %v", err)
+		c.fail(grpcReason(err), err)
+		if c.reason == storage.ReasonTLS && !c.canceled {
+			c.cert = p.inspectTLS(reqCtx, u)
+		}
+		return c
+	}
+	if info, ok := pr.AuthInfo.(credentials.TLSInfo); ok {
+		c.cert = p.certInfo(info.State.PeerCertificates, nil, time.Now())
+	}
+
+	c.latency = elapsed.Nanoseconds()
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		c.fail(storage.ReasonNotServing, nil)
+		c.err = resp.Status.String()
+		return c
+	}
+	c.isAvailable = true
+	return c
+}
+
+// grpcReason определяет причину недоступности по ошибке вызова Health/Check.
+func grpcReason(err error) string {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return storage.ReasonTimeout
+	case codes.NotFound:
+		return storage.ReasonNotServing // сервис неизвестен серверу
+	case codes.Unavailable:
+		// причина ошибки подключения доступна только в тексте ошибки
+		if reason := classifyError(err); reason != storage.ReasonUnknown {
+			return reason
+		}
+		if strings.Contains(err.Error(), "no such host") {
+			return storage.ReasonDNS
+		}
+		return storage.ReasonConnect
+	}
+	return classifyError(err)
+}
@@ -0,0 +1,135 @@
+package poller
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// Пороги открытия и закрытия инцидентов по умолчанию
+const (
+	defaultIncidentOpen  = 3
+	defaultIncidentClose = 2
+)
+
+// incidentTracker группирует последовательные неудачные проверки ресурса
+// в инциденты: инцидент открывается после openAfter неудачных проверок подряд
+// и закрывается после closeAfter успешных проверок подряд.
+type incidentTracker struct {
+	db         storage.Storage
+	openAfter  int
+	closeAfter int
+
+	mu     sync.Mutex
+	states map[string]*incidentState
+}
+
+type incidentState struct {
+	failures     int   // число неудачных проверок подряд
+	firstFailure *call // первая неудачная проверка серии
+	successes    int   // число успешных проверок подряд
+	firstSuccess time.Time
+	open         *storage.Incident
+}
+
+// newIncidentTracker создает трекер и восстанавливает открытые инциденты из базы.
+func newIncidentTracker(db storage.Storage, openAfter, closeAfter int) (*incidentTracker, error) {
+	if openAfter <= 0 {
+		openAfter = defaultIncidentOpen
+	}
+	if closeAfter <= 0 {
+		closeAfter = defaultIncidentClose
+	}
+	t := &incidentTracker{
+		db:         db,
+		openAfter:  openAfter,
+		closeAfter: closeAfter,
+		states:     make(map[string]*incidentState),
+	}
+
+	incidents, err := db.ListIncidents("", time.Time{}, time.Now())
+	if err != nil {
+		return t, err
+	}
+	for i := range incidents {
+		if inc := incidents[i]; inc.IsOpen() {
+			t.states[inc.URL] = &incidentState{open: &inc}
+		}
+	}
+	return t, nil
+}
+
+// track учитывает результат проверки и сохраняет открытый или закрытый инцидент.
+func (t *incidentTracker) track(c *call) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[c.url]
+	if !ok {
+		st = new(incidentState)
+		t.states[c.url] = st
+	}
+
+	if !c.isAvailable {
+		st.successes = 0
+		if st.failures == 0 {
+			st.firstFailure = c
+		}
+		st.failures++
+		if st.open != nil || st.failures < t.openAfter {
+			return nil
+		}
+		st.open = &storage.Incident{
+			URL:    c.url,
+			Start:  st.firstFailure.time,
+			Reason: st.firstFailure.reason,
+			Error:  st.firstFailure.err,
+		}
+		log.Printf("[WARN] Incident opened: %s %s", c.url, st.open.Reason)
+		return t.db.PutIncident(st.open)
+	}
+
+	st.failures = 0
+	st.firstFailure = nil
+	if st.open == nil {
+		return nil
+	}
+	if st.successes == 0 {
+		st.firstSuccess = c.time
+	}
+	st.successes++
+	if st.successes < t.closeAfter {
+		return nil
+	}
+	inc := st.open
+	inc.End = st.firstSuccess
+	st.open = nil
+	st.successes = 0
+	log.Printf("[INFO] Incident closed: %s after %v", c.url, inc.Duration(c.time))
+	return t.db.PutIncident(inc)
+}
+
+// retain закрывает временем end открытые инциденты ресурсов, для которых keep
+// возвращает false, и удаляет их состояние, чтобы инцидент удаленного ресурса
+// не оставался открытым.
+func (t *incidentTracker) retain(keep func(url string) bool, end time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for url, st := range t.states {
+		if keep(url) {
+			continue
+		}
+		delete(t.states, url)
+		if st.open == nil {
+			continue
+		}
+		inc := st.open
+		inc.End = end
+		log.Printf("[INFO] Incident closed: %s removed after %v", url, inc.Duration(end))
+		if err := t.db.PutIncident(inc); err != nil {
+			log.Println("[ERROR] Can't PutIncident", err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package poller
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Политики для опроса, время которого наступило, пока предыдущий опрос
+// того же ресурса еще не завершен
+const (
+	OverrunSkip  = "skip"  // опрос пропускается
+	OverrunQueue = "queue" // опрос выполняется сразу после завершения предыдущего
+)
+
+// lateRatio - доля периода ресурса, на которую опрос может начаться позже
+// запланированного, не считаясь опоздавшим.
+const lateRatio = 10
+
+// Stats - счетчики опросов с момента запуска поллера. Рост Skipped, Queued
+// и Late означает, что воркеров не хватает для опроса ресурсов с их периодом.
+type Stats struct {
+	Scheduled uint64 // запланировано опросов
+	Skipped   uint64 // пропущено, т.к. предыдущий опрос ресурса не завершен
+	Queued    uint64 // отложено до завершения предыдущего опроса ресурса
+	Late      uint64 // начато позже запланированного более чем на 1/lateRatio периода
+	InFlight  int    // ресурсов, опрос которых запланирован или выполняется
+}
+
+type stats struct {
+	scheduled, skipped, queued, late uint64
+}
+
+// Stats возвращает счетчики опросов.
+func (p *Poller) Stats() Stats {
+	p.init()
+	s := Stats{
+		Scheduled: atomic.LoadUint64(&p.stats.scheduled),
+		Skipped:   atomic.LoadUint64(&p.stats.skipped),
+		Queued:    atomic.LoadUint64(&p.stats.queued),
+		Late:      atomic.LoadUint64(&p.stats.late),
+	}
+	p.flights.mu.Lock()
+	s.InFlight = len(p.flights.running)
+	p.flights.mu.Unlock()
+	return s
+}
+
+// flights отслеживает ресурсы, опрос которых передан воркерам и еще не завершен.
+type flights struct {
+	policy string
+
+	mu      sync.Mutex
+	running map[string]*job // отложенный опрос ресурса или nil
+}
+
+func newFlights(policy string) *flights {
+	switch policy {
+	case "":
+		policy = OverrunSkip
+	case OverrunSkip, OverrunQueue:
+	default:
+		log.Printf("[ERROR] Unknown overrun policy %q, use %q", policy, OverrunSkip)
+		policy = OverrunSkip
+	}
+	return &flights{policy: policy, running: make(map[string]*job)}
+}
+
+// acquire отмечает начало опроса j и возвращает true, если его можно
+// передать воркеру. Если предыдущий опрос ресурса еще не завершен, то опрос
+// пропускается или откладывается до его завершения, см. release.
+func (f *flights) acquire(j *job, st *stats) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	url := j.target.URL
+	queued, running := f.running[url]
+	if !running {
+		f.running[url] = nil
+		return true
+	}
+	if f.policy == OverrunQueue && queued == nil {
+		f.running[url] = j
+		atomic.AddUint64(&st.queued, 1)
+		log.Println("[WARN] Probe is queued, previous is in flight:", url)
+		return false
+	}
+	atomic.AddUint64(&st.skipped, 1)
+	log.Println("[WARN] Probe is skipped, previous is in flight:", url)
+	return false
+}
+
+// release отмечает завершение опроса ресурса и возвращает отложенный опрос, если он есть.
+func (f *flights) release(url string) *job {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queued := f.running[url]
+	if queued == nil {
+		delete(f.running, url)
+		return nil
+	}
+	f.running[url] = nil
+	return queued
+}
+
+// checkLate учитывает опрос j, начатый в момент now, как опоздавший,
+// если он начат слишком поздно.
+func (p *Poller) checkLate(j *job, now time.Time) {
+	delay := now.Sub(j.at)
+	if delay > j.target.interval(p.Interval)/lateRatio {
+		atomic.AddUint64(&p.stats.late, 1)
+		log.Printf("[WARN] Probe of %s is late by %v", j.target.URL, delay)
+	}
+}
@@ -1,131 +1,767 @@
 package poller
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
-	"net"
 	"net/http"
+	"net/http/httptrace"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/akosourov/monitoring/storage"
 )
 
-// Poller периодически с интервалом Interval опрашивает список http(s) ресурсов URLs
-// и замеряет доступность и время отклика, сохраняя результат в базу.
-// За открытие и закрытие подключения к БД должен отвечать клиент.
+// Poller периодически опрашивает список ресурсов URLs и Targets (http(s), tcp,
+// dns и grpc, см. Target.Kind) и замеряет доступность и время отклика,
+// сохраняя результат в базу.
+// Каждый ресурс опрашивается со своим периодом Target.Interval и временем
+// ожидания Target.Timeout, по умолчанию - Interval и Timeout.
+// Http ресурсы из URLs опрашиваются HEAD запросом с настройками проверки по умолчанию.
+// Список ресурсов можно менять во время работы методами AddTarget, UpdateTarget
+// и RemoveTarget. За открытие и закрытие подключения к БД должен отвечать клиент.
 type Poller struct {
 	URLs     []string
+	Targets  []Target
 	Interval time.Duration
 	Timeout  time.Duration
 	Workers  int
 	DB       storage.Storage
 
-	client http.Client
-	stop   chan struct{}
+	// число неудачных проверок подряд для открытия инцидента и успешных
+	// для его закрытия, по умолчанию 3 и 2
+	IncidentOpen  int
+	IncidentClose int
+
+	// способ распределения опросов по периоду (SpreadHash, SpreadNone),
+	// по умолчанию SpreadHash, и максимальная случайная задержка каждого опроса
+	Spread string
+	Jitter time.Duration
+
+	// политика для опроса, время которого наступило раньше завершения
+	// предыдущего опроса ресурса (OverrunSkip, OverrunQueue), по умолчанию OverrunSkip
+	Overrun string
+
+	// пороги процента смены состояния за последние 21 проверку для
+	// признания ресурса нестабильным и стабильным, по умолчанию 50 и 25
+	FlapHigh float64
+	FlapLow  float64
+
+	// получатели результатов проверок, вызываются после сохранения в БД
+	Observers []Observer
+
+	// корневые сертификаты для проверки https и grpcs ресурсов, nil - системные
+	RootCAs *x509.CertPool
+
+	// время ожидания завершения начатых проверок при остановке, после
+	// которого запросы прерываются, по умолчанию 5 секунд
+	ShutdownTimeout time.Duration
+
+	// за сколько дней до окончания действия сертификата https ресурса
+	// предупреждать, по умолчанию 14; отрицательное значение - не предупреждать
+	CertWarnDays int
+
+	// число ошибок записи в БД подряд, после которого поллер останавливается
+	// с ошибкой, по умолчанию 5; отрицательное значение - не останавливаться
+	MaxWriteErrors int
+
+	client      http.Client
+	incidents   *incidentTracker
+	flaps       *flapDetector
+	flights     *flights
+	stats       stats
+	writeErrors int32 // ошибок записи в БД подряд
+
+	once    sync.Once
+	mu      sync.RWMutex
+	targets []*Target     // опрашиваемые ресурсы, заменяются целиком при изменении
+	changed chan struct{} // сигнал планировщику об изменении списка ресурсов
+
+	started  int32         // поллер запущен, повторный запуск невозможен
+	stop     chan struct{} // закрывается в Stop
+	stopOnce sync.Once
+	done     chan struct{} // закрывается по окончании работы поллера
+	err      error         // ошибка, с которой остановился поллер
 }
 
-// Start инициирует запуск поллера в работу. Он будет работать до тех пор,
-// пока не будет вызван метод Stop или не случится ошибка записи в БД.
-func (p *Poller) Start() {
+// Observer получает результаты проверок ресурсов, например для оповещений.
+// Observe вызывается конкурентно из нескольких горутин.
+type Observer interface {
+	Observe(url string, r *storage.Result)
+}
+
+// ErrStarted возвращается при повторном запуске поллера.
+var ErrStarted = errors.New("poller: already started")
+
+const (
+	defaultShutdownTimeout = 5 * time.Second
+	defaultMaxWriteErrors  = 5
+)
+
+// Run опрашивает ресурсы, пока не будет отменен ctx, вызван метод Stop
+// или не случится ошибка, после которой продолжать опрос бессмысленно
+// (см. MaxWriteErrors). Перед возвратом дожидается завершения начатых
+// проверок, но не дольше ShutdownTimeout, после чего запросы прерываются.
+// При штатной остановке возвращает nil. Поллер запускается только один раз
+// и только с положительным Interval.
+func (p *Poller) Run(ctx context.Context) error {
+	if err := p.begin(); err != nil {
+		return err
+	}
+	p.end(p.run(ctx))
+	return p.err
+}
+
+// Start запускает поллер, как Run, в отдельной горутине. Ошибку, с которой
+// поллер остановился, возвращают Wait и Stop.
+func (p *Poller) Start(ctx context.Context) error {
+	if err := p.begin(); err != nil {
+		return err
+	}
+	go func() {
+		p.end(p.run(ctx))
+	}()
+	return nil
+}
+
+// Stop останавливает поллер и дожидается окончания его работы. Повторный
+// вызов безопасен и возвращает ту же ошибку. Поллер, остановленный до
+// запуска, не начнет опрос.
+func (p *Poller) Stop() error {
+	p.init()
+	p.stopOnce.Do(func() { close(p.stop) })
+	return p.Wait()
+}
+
+// Wait дожидается окончания работы поллера и возвращает ошибку, с которой
+// он остановился. Если поллер не запущен, возвращает nil сразу.
+func (p *Poller) Wait() error {
+	p.init()
+	if atomic.LoadInt32(&p.started) == 0 {
+		return nil
+	}
+	<-p.done
+	return p.err
+}
+
+func (p *Poller) begin() error {
+	p.init()
+	if p.Interval <= 0 {
+		return fmt.Errorf("poller: interval must be positive, got %v", p.Interval)
+	}
+	if !atomic.CompareAndSwapInt32(&p.started, 0, 1) {
+		return ErrStarted
+	}
+	return nil
+}
+
+func (p *Poller) end(err error) {
+	if err != nil {
+		log.Println("[ERROR] Poller failed:", err)
+	}
+	p.err = err
+	close(p.done)
+}
+
+func (p *Poller) run(ctx context.Context) error {
+	select {
+	case <-p.stop:
+		return nil
+	case <-ctx.Done():
+		return nil
+	default:
+	}
 	log.Println("[INFO] Start polling with interval", p.Interval)
 
-	p.client = http.Client{
-		Timeout: p.Timeout,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse // prevent redirect
-		},
+	p.client = newHTTPClient(p.RootCAs)
+	incidents, err := newIncidentTracker(p.DB, p.IncidentOpen, p.IncidentClose)
+	if err != nil {
+		log.Println("[ERROR] Can't load open incidents", err)
 	}
-	p.stop = make(chan struct{})
+	p.mu.Lock()
+	p.incidents, p.flaps = incidents, newFlapDetector(p.FlapLow, p.FlapHigh)
+	// закрываем инциденты ресурсов, удаленных до перезапуска
+	p.forgetRemoved(p.targets)
+	p.mu.Unlock()
+
+	// запросы прерываются не при остановке, а по истечении ShutdownTimeout,
+	// чтобы начатые проверки успели завершиться и сохраниться
+	probeCtx, cancelProbes := context.WithCancel(context.Background())
+	defer cancelProbes()
 
 	var (
-		wgp sync.WaitGroup // pollers
-		wgs sync.WaitGroup // savers to db
+		wgp   sync.WaitGroup // pollers
+		wgs   sync.WaitGroup // savers to db
+		fatal = make(chan error, 1)
 	)
-	in := make(chan string)
+	in := make(chan *job)
 	out := make(chan *call)
 
 	for i := 0; i < p.Workers; i++ {
 		wgp.Add(1)
-		go p.poll(in, out, &wgp)
+		go p.poll(probeCtx, in, out, &wgp)
 		wgs.Add(1)
-		go p.saveCall(out, &wgs)
+		go p.saveCall(out, fatal, &wgs)
 	}
 
-	tick := time.Tick(p.Interval)
+	sched := newScheduler(p.Interval, p.Spread, p.Jitter)
+	sched.sync(p.currentTargets(), time.Now())
+	timer := time.NewTimer(sched.wait(time.Now()))
+	defer timer.Stop()
+
+	// опросы, ожидающие свободного воркера; планировщик не блокируется
+	// на отправке, чтобы не пропускать время следующих опросов
+	var pending []*job
+loop:
 	for {
-		select {
-		case <-tick:
-			log.Println("[DEBUG] tick")
+		var (
+			send chan<- *job
+			next *job
+		)
+		if len(pending) > 0 {
+			send, next = in, pending[0]
+		}
 
-			for _, url := range p.URLs {
-				in <- url
+		select {
+		case send <- next:
+			pending[0] = nil
+			pending = pending[1:]
+		case <-timer.C:
+			for _, j := range sched.due(time.Now()) {
+				atomic.AddUint64(&p.stats.scheduled, 1)
+				if p.flights.acquire(j, &p.stats) {
+					pending = append(pending, j)
+				}
+			}
+			timer.Reset(sched.wait(time.Now()))
+		case <-p.changed:
+			sched.sync(p.currentTargets(), time.Now())
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
 			}
+			timer.Reset(sched.wait(time.Now()))
+		case <-ctx.Done():
+			break loop
 		case <-p.stop:
-			log.Println("[WARN] Stop poller")
-			close(in)
-			wgp.Wait()
-			// ждем обработки записи в бд оставшихся работ
-			close(out)
-			wgs.Wait()
-			p.stop <- struct{}{}
-			log.Println("[INFO] Poller was stoped")
-			return
+			break loop
+		case err = <-fatal:
+			break loop
 		}
 	}
+
+	log.Println("[WARN] Stop poller")
+	close(in)
+	if !waitTimeout(&wgp, p.shutdownTimeout()) {
+		log.Println("[WARN] Shutdown timeout, cancel probes in flight")
+		cancelProbes()
+		wgp.Wait()
+	}
+	// ждем обработки записи в бд оставшихся работ
+	close(out)
+	wgs.Wait()
+	log.Println("[INFO] Poller was stoped")
+	return err
+}
+
+// newHTTPClient возвращает клиент для проверки http ресурсов. Соединение
+// устанавливается на каждую проверку, чтобы время отклика и его этапы
+// (dns, подключение, tls) не зависели от повторного использования соединений.
+// Сертификаты проверяются по roots, nil - по системным. Время ожидания
+// задается для каждого запроса, см. probe.
+func newHTTPClient(roots *x509.CertPool) http.Client {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.DisableKeepAlives = true
+	tr.TLSClientConfig = &tls.Config{RootCAs: roots}
+	return http.Client{
+		Transport: tr,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse // prevent redirect
+		},
+	}
 }
 
-func (p *Poller) Stop() {
-	p.stop <- struct{}{} // сигнал завершения выполнения
-	<-p.stop             // ожидание факта окончания работы поллера
+func (p *Poller) shutdownTimeout() time.Duration {
+	if p.ShutdownTimeout > 0 {
+		return p.ShutdownTimeout
+	}
+	return defaultShutdownTimeout
+}
+
+// maxWriteErrors возвращает число ошибок записи подряд для остановки поллера,
+// 0 - не останавливаться.
+func (p *Poller) maxWriteErrors() int32 {
+	switch {
+	case p.MaxWriteErrors < 0:
+		return 0
+	case p.MaxWriteErrors == 0:
+		return defaultMaxWriteErrors
+	}
+	return int32(p.MaxWriteErrors)
+}
+
+// waitTimeout ждет wg не дольше d и возвращает false, если время истекло.
+func waitTimeout(wg *sync.WaitGroup, d time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-done:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+// init создает каналы управления поллером и заполняет список опрашиваемых
+// ресурсов из URLs и Targets, пропуская ресурсы с ошибками в настройках и повторы url.
+func (p *Poller) init() {
+	p.once.Do(func() {
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		p.changed = make(chan struct{}, 1)
+		p.flights = newFlights(p.Overrun)
+
+		targets := make([]*Target, 0, len(p.URLs)+len(p.Targets))
+		for _, url := range p.URLs {
+			targets = append(targets, &Target{URL: url})
+		}
+		for i := range p.Targets {
+			t := p.Targets[i]
+			targets = append(targets, &t)
+		}
+
+		seen := make(map[string]bool)
+		for _, t := range targets {
+			if err := t.Validate(); err != nil {
+				log.Println("[ERROR] Skip target:", err)
+				continue
+			}
+			if seen[t.URL] {
+				log.Println("[ERROR] Skip duplicate target:", t.URL)
+				continue
+			}
+			seen[t.URL] = true
+			p.targets = append(p.targets, t)
+		}
+	})
+}
+
+// ActiveTargets возвращает копию списка опрашиваемых ресурсов.
+func (p *Poller) ActiveTargets() []Target {
+	p.init()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	targets := make([]Target, len(p.targets))
+	for i, t := range p.targets {
+		targets[i] = *t
+	}
+	return targets
+}
+
+// AddTarget добавляет ресурс для опроса, начиная со следующего тика.
+func (p *Poller) AddTarget(t Target) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	return p.modify(func(targets []*Target) ([]*Target, error) {
+		if indexOf(targets, t.URL) >= 0 {
+			return nil, fmt.Errorf("%s: target already exists", t.URL)
+		}
+		log.Println("[INFO] Add target", t.URL)
+		return append(targets, &t), nil
+	})
+}
+
+// UpdateTarget заменяет настройки ресурса с url t.URL. Уже начатые проверки
+// завершаются и сохраняются с прежними настройками.
+func (p *Poller) UpdateTarget(t Target) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	return p.modify(func(targets []*Target) ([]*Target, error) {
+		i := indexOf(targets, t.URL)
+		if i < 0 {
+			return nil, fmt.Errorf("%s: target not exists", t.URL)
+		}
+		log.Println("[INFO] Update target", t.URL)
+		targets[i] = &t
+		return targets, nil
+	})
+}
+
+// RemoveTarget прекращает опрос ресурса. Результат уже начатой проверки
+// будет сохранен, а запланированные, но не начатые проверки не выполняются.
+func (p *Poller) RemoveTarget(url string) error {
+	return p.modify(func(targets []*Target) ([]*Target, error) {
+		i := indexOf(targets, url)
+		if i < 0 {
+			return nil, fmt.Errorf("%s: target not exists", url)
+		}
+		log.Println("[INFO] Remove target", url)
+		return append(targets[:i], targets[i+1:]...), nil
+	})
+}
+
+// TargetsDiff - url ресурсов, добавленных, измененных и удаленных при замене
+// списка ресурсов.
+type TargetsDiff struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// Empty возвращает true, если список ресурсов не изменился.
+func (d TargetsDiff) Empty() bool {
+	return len(d.Added)+len(d.Updated)+len(d.Removed) == 0
+}
+
+func (d TargetsDiff) String() string {
+	return fmt.Sprintf("added %v, updated %v, removed %v", d.Added, d.Updated, d.Removed)
+}
+
+// SetTargets заменяет список опрашиваемых ресурсов на targets и возвращает
+// изменения. Если настройки какого-либо ресурса неверны, то список не меняется.
+// Опрос неизмененных ресурсов продолжается без перерыва.
+func (p *Poller) SetTargets(targets []Target) (TargetsDiff, error) {
+	var diff TargetsDiff
+	next := make([]*Target, 0, len(targets))
+	for i := range targets {
+		t := targets[i]
+		if err := t.Validate(); err != nil {
+			return diff, err
+		}
+		if indexOf(next, t.URL) >= 0 {
+			return diff, fmt.Errorf("%s: duplicate target", t.URL)
+		}
+		next = append(next, &t)
+	}
+
+	err := p.modify(func(current []*Target) ([]*Target, error) {
+		for i, t := range next {
+			j := indexOf(current, t.URL)
+			switch {
+			case j < 0:
+				diff.Added = append(diff.Added, t.URL)
+			case !sameTarget(t, current[j]):
+				diff.Updated = append(diff.Updated, t.URL)
+			default:
+				next[i] = current[j]
+			}
+		}
+		for _, t := range current {
+			if indexOf(next, t.URL) < 0 {
+				diff.Removed = append(diff.Removed, t.URL)
+			}
+		}
+		return next, nil
+	})
+	return diff, err
+}
+
+// sameTarget сравнивает настройки ресурсов, не учитывая скомпилированные регулярные выражения.
+func sameTarget(a, b *Target) bool {
+	ja, erra := json.Marshal(a)
+	jb, errb := json.Marshal(b)
+	return erra == nil && errb == nil && bytes.Equal(ja, jb)
+}
+
+// modify применяет fn к копии списка ресурсов и заменяет им текущий список,
+// чтобы не менять срез, по которому может идти рассылка заданий.
+func (p *Poller) modify(fn func(targets []*Target) ([]*Target, error)) error {
+	p.init()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	targets, err := fn(append([]*Target(nil), p.targets...))
+	if err != nil {
+		return err
+	}
+	p.targets = targets
+	p.forgetRemoved(targets)
+	select {
+	case p.changed <- struct{}{}:
+	default: // планировщик еще не обработал предыдущее изменение
+	}
+	return nil
+}
+
+func (p *Poller) currentTargets() []*Target {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.targets
+}
+
+// forgetRemoved закрывает открытые инциденты и удаляет состояние ресурсов,
+// которых нет в targets. Вызывается под p.mu.
+func (p *Poller) forgetRemoved(targets []*Target) {
+	if p.incidents == nil {
+		return // опрос еще не запущен
+	}
+	keep := func(url string) bool { return indexOf(targets, url) >= 0 }
+	p.incidents.retain(keep, time.Now())
+	p.flaps.retain(keep)
+}
+
+// ifTarget вызывает fn под p.mu, если ресурс url есть в списке опрашиваемых,
+// чтобы результат начатой до удаления проверки не вернул состояние ресурса
+// после forgetRemoved.
+func (p *Poller) ifTarget(url string, fn func()) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if indexOf(p.targets, url) >= 0 {
+		fn()
+	}
+}
+
+// hasTarget возвращает true, если ресурс url есть в списке опрашиваемых.
+func (p *Poller) hasTarget(url string) bool {
+	return indexOf(p.currentTargets(), url) >= 0
+}
+
+func indexOf(targets []*Target, url string) int {
+	for i, t := range targets {
+		if t.URL == url {
+			return i
+		}
+	}
+	return -1
 }
 
 type call struct {
 	url         string
+	time        time.Time // время начала проверки
 	isAvailable bool
 	latency     int64
+	phases      *storage.Phases
+	status      int
+	reason      string // причина недоступности, см. storage.Reason*
+	check       string // непройденная проверка ответа
+	err         string
+	canceled    bool              // проверка прервана при остановке поллера, не сохраняется
+	attempts    int               // число попыток, см. Retry
+	cert        *storage.CertInfo // сертификаты https ресурса
 }
 
-func (p *Poller) poll(in <-chan string, out chan<- *call, wg *sync.WaitGroup) {
-	for url := range in {
-		c, err := p.doHEAD(url)
-		if err != nil {
-			continue
+// fail отмечает ресурс недоступным по причине reason.
+func (c *call) fail(reason string, err error) {
+	c.isAvailable = false
+	c.reason = reason
+	if err != nil {
+		c.err = err.Error()
+	}
+}
+
+func (p *Poller) poll(ctx context.Context, in <-chan *job, out chan<- *call, wg *sync.WaitGroup) {
+	for j := range in {
+		// отложенный опрос ресурса выполняется тем же воркером сразу после текущего
+		for ; j != nil; j = p.flights.release(j.target.URL) {
+			// опрос мог ждать воркера или завершения предыдущего опроса,
+			// пока ресурс удаляли
+			if !p.hasTarget(j.target.URL) {
+				log.Println("[INFO] Probe dropped, target was removed:", j.target.URL)
+				continue
+			}
+			p.checkLate(j, time.Now())
+			c := p.probe(ctx, j.target)
+			if c.canceled {
+				log.Println("[WARN] Probe canceled:", c.url)
+				continue
+			}
+			out <- c
 		}
-		out <- c
 	}
 	wg.Done()
 }
 
-func (p *Poller) saveCall(out <-chan *call, wg *sync.WaitGroup) {
-	var lat int64
+// saveCall сохраняет результаты проверок. Если запись в БД не удается
+// maxWriteErrors раз подряд, то ошибка отправляется в fatal.
+func (p *Poller) saveCall(out <-chan *call, fatal chan<- error, wg *sync.WaitGroup) {
 	for c := range out {
-		lat = c.latency
+		res := &storage.Result{
+			Time:    c.time,
+			Latency: c.latency,
+			Phases:  c.phases,
+			Status:  c.status,
+			Reason:  c.reason,
+			Check:   c.check,
+			Error:   c.err,
+
+			Attempts: c.attempts,
+			Retried:  c.isAvailable && c.attempts > 1,
+
+			Certificate: c.cert,
+		}
 		if !c.isAvailable {
-			lat = -1
+			res.Latency = -1
+		}
+		p.ifTarget(c.url, func() {
+			res.Flapping, res.StateChange = p.flaps.track(c)
+		})
+		if ok, err := p.DB.InMaintenance(c.url, c.time); err != nil {
+			log.Println("[ERROR] Can't check maintenance", err)
+		} else {
+			res.Maintenance = ok
 		}
 		log.Printf("[DEBUG] SAVE: %+v", *c)
-		if err := p.DB.PutLatency(c.url, lat); err != nil {
-			log.Println("[ERROR] Can't PutLatency", err)
+		if err := p.DB.PutResult(c.url, res); err != nil {
+			log.Println("[ERROR] Can't PutResult", err)
+			n := atomic.AddInt32(&p.writeErrors, 1)
+			if max := p.maxWriteErrors(); max > 0 && n >= max {
+				select {
+				case fatal <- fmt.Errorf("poller: %d db write errors in a row: %v", n, err):
+				default: // поллер уже останавливается
+				}
+			}
+		} else {
+			atomic.StoreInt32(&p.writeErrors, 0)
+		}
+		if c.cert != nil {
+			if err := p.DB.PutCertificate(c.url, c.cert); err != nil {
+				log.Println("[ERROR] Can't PutCertificate", err)
+			}
+		}
+		p.ifTarget(c.url, func() {
+			if err := p.incidents.track(c); err != nil {
+				log.Println("[ERROR] Can't PutIncident", err)
+			}
+		})
+		for _, o := range p.Observers {
+			o.Observe(c.url, res)
 		}
 	}
 	wg.Done()
 }
 
-func (p *Poller) doHEAD(url string) (*call, error) {
+// maxBodySize - ограничение на размер тела ответа, читаемого для проверок.
+const maxBodySize = 1 << 20
+
+// attempt выполняет одну попытку проверки ресурса в зависимости от его вида.
+// Любой исход проверки, в том числе ошибка запроса, возвращается в виде call.
+// Проверка прерывается при отмене ctx.
+func (p *Poller) attempt(ctx context.Context, t *Target) *call {
+	switch t.Kind() {
+	case KindTCP:
+		return p.probeTCP(ctx, t)
+	case KindDNS:
+		return p.probeDNS(ctx, t)
+	case KindGRPC:
+		return p.probeGRPC(ctx, t)
+	}
+	return p.probeHTTP(ctx, t)
+}
+
+// probeHTTP выполняет запрос к ресурсу и проверяет ответ согласно настройкам t.
+func (p *Poller) probeHTTP(ctx context.Context, t *Target) *call {
 	c := new(call)
-	c.url = url
+	c.url = t.URL
+	c.time = time.Now()
+
+	var body io.Reader
+	if t.Body != "" {
+		body = strings.NewReader(t.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.Method, t.URL, body)
+	if err != nil {
+		log.Printf("[ERROR] bad request: %v", err)
+		c.fail(storage.ReasonUnknown, err)
+		return c
+	}
+
+	for k, v := range t.Headers {
+		if http.CanonicalHeaderKey(k) == "Host" {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	reqCtx := ctx
+	if timeout := t.timeout(p.Timeout); timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(reqCtx, timeout)
+		defer cancel()
+	}
+	pt := new(phaseTimer)
+	req = req.WithContext(httptrace.WithClientTrace(reqCtx, pt.trace()))
+
 	start := time.Now()
-	_, err := p.client.Head(url)
-	if e, ok := err.(net.Error); ok && e.Timeout() {
-		// timeout error
-		return c, nil // c.isAvailable = false
-	} else if err != nil {
-		log.Printf("[ERROR] http error: %v", err)
-		return nil, err
+	resp, err := p.client.Do(req)
+	if err != nil {
+		c.canceled = ctx.Err() != nil
+		log.Printf("[WARN] http error: %v", err)
+		c.fail(classifyError(err), err)
+		if c.reason == storage.ReasonTLS && !c.canceled {
+			c.cert = p.inspectTLS(reqCtx, req.URL)
+		}
+		return c
+	}
+	defer resp.Body.Close()
+	if resp.TLS != nil {
+		c.cert = p.certInfo(resp.TLS.PeerCertificates, nil, time.Now())
 	}
 
-	elapsed := time.Since(start)
-	c.isAvailable = true
+	// тело читается целиком, чтобы замерить время его получения,
+	// но для проверок сохраняется не более maxBodySize байт
+	buf := bytes.NewBuffer(nil)
+	var w io.Writer = ioutil.Discard
+	if len(t.Checks) > 0 {
+		w = &limitedWriter{buf, maxBodySize}
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		c.canceled = ctx.Err() != nil
+		log.Printf("[WARN] http error: %v", err)
+		c.fail(classifyError(err), err)
+		return c
+	}
+	pt.bodyDone = time.Now()
+	respBody := buf.Bytes()
+
+	elapsed := pt.bodyDone.Sub(start)
 	c.latency = elapsed.Nanoseconds()
-	return c, err
+	c.phases = pt.phases()
+	c.status = resp.StatusCode
+
+	if check, ok := t.checkStatus(resp.StatusCode); !ok {
+		c.fail(storage.ReasonHTTPStatus, nil)
+		c.check = check
+		return c
+	}
+	if check, ok := t.checkBody(respBody); !ok {
+		c.fail(storage.ReasonBodyMismatch, nil)
+		c.check = check
+		return c
+	}
+	c.isAvailable = true
+	return c
+}
+
+// limitedWriter пишет в буфер не более n байт, отбрасывая остальное.
+type limitedWriter struct {
+	buf *bytes.Buffer
+	n   int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if rest := w.n - w.buf.Len(); rest > 0 {
+		if len(p) < rest {
+			rest = len(p)
+		}
+		w.buf.Write(p[:rest])
+	}
+	return len(p), nil
 }
@@ -0,0 +1,1167 @@
+package poller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpkg "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+func newTestPoller() *Poller {
+	return &Poller{client: http.Client{Timeout: time.Second}}
+}
+
+func TestProbeRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/slow" && n == 1:
+			time.Sleep(200 * time.Millisecond)
+		case r.URL.Path == "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	reset := func() {
+		mu.Lock()
+		requests = 0
+		mu.Unlock()
+	}
+
+	p := newTestPoller()
+	p.Timeout = 50 * time.Millisecond
+
+	// первая попытка превысила время ожидания, вторая успешна
+	target := &Target{URL: srv.URL + "/slow", Retry: &Retry{Attempts: 3, Backoff: 10 * time.Millisecond}}
+	assert.Nil(t, target.Validate())
+	start := time.Now()
+	c := p.probe(context.Background(), target)
+	assert.True(t, c.isAvailable)
+	assert.Equal(t, 2, c.attempts)
+	assert.WithinDuration(t, start, c.time, 20*time.Millisecond, "time of first attempt")
+
+	// http_status по умолчанию не повторяется
+	reset()
+	target = &Target{URL: srv.URL + "/down", Retry: &Retry{Attempts: 3}}
+	assert.Nil(t, target.Validate())
+	c = p.probe(context.Background(), target)
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, 1, c.attempts)
+
+	reset()
+	target.Retry.On = []string{storage.ReasonHTTPStatus}
+	c = p.probe(context.Background(), target)
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonHTTPStatus, c.reason)
+	assert.Equal(t, 3, c.attempts)
+	mu.Lock()
+	assert.Equal(t, 3, requests)
+	mu.Unlock()
+
+	assert.Error(t, (&Target{URL: srv.URL, Retry: &Retry{}}).Validate())
+	assert.Error(t, (&Target{URL: srv.URL, Retry: &Retry{Attempts: maxRetryAttempts + 1}}).Validate())
+	assert.Error(t, (&Target{URL: srv.URL, Retry: &Retry{Attempts: 2, Backoff: -1}}).Validate())
+	assert.Error(t, (&Target{URL: srv.URL, Retry: &Retry{Attempts: 2, On: []string{"oops"}}}).Validate())
+}
+
+func TestRetryBackoff(t *testing.T) {
+	r := &Retry{Attempts: maxRetryAttempts, Backoff: 100 * time.Millisecond}
+	assert.Equal(t, 100*time.Millisecond, r.backoff(1))
+	assert.Equal(t, 400*time.Millisecond, r.backoff(3))
+	assert.Equal(t, maxRetryBackoff, r.backoff(20))
+
+	// удвоение большой паузы не переполняется
+	r.Backoff = time.Duration(1) << 62
+	assert.Equal(t, maxRetryBackoff, r.backoff(1))
+	assert.Equal(t, maxRetryBackoff, r.backoff(100))
+}
+
+func TestProbeTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 64)
+				n, _ := conn.Read(buf)
+				if string(buf[:n]) == "PING\r\n" {
+					conn.Write([]byte("+PONG\r\n"))
+				}
+			}()
+		}
+	}()
+	addr := "tcp://" + ln.Addr().String()
+
+	p := newTestPoller()
+	p.Timeout = 100 * time.Millisecond
+	probe := func(target *Target) *call {
+		assert.Nil(t, target.Validate())
+		return p.probe(context.Background(), target)
+	}
+
+	c := probe(&Target{URL: addr})
+	assert.True(t, c.isAvailable)
+	assert.True(t, c.latency > 0)
+	assert.NotZero(t, c.phases.Connect)
+
+	c = probe(&Target{URL: addr, Send: "PING\r\n", Expect: "PONG"})
+	assert.True(t, c.isAvailable, c.err)
+	assert.NotZero(t, c.phases.TTFB)
+
+	c = probe(&Target{URL: addr, Send: "HELLO\r\n", Expect: "PONG"})
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonBodyMismatch, c.reason)
+	assert.Equal(t, `expect "PONG"`, c.check)
+
+	// сервер ждет команду и не присылает приветствие
+	c = probe(&Target{URL: addr, Expect: "+OK"})
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonTimeout, c.reason)
+
+	// сервер присылает другое приветствие и не закрывает соединение
+	banner, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer banner.Close()
+	go func() {
+		for {
+			conn, err := banner.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte("-ERR busy\r\n"))
+				io.Copy(ioutil.Discard, conn)
+			}()
+		}
+	}()
+	c = probe(&Target{URL: "tcp://" + banner.Addr().String(), Expect: "+OK"})
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonBodyMismatch, c.reason)
+	assert.Contains(t, c.err, `got "-ERR busy\r\n"`)
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	closed.Close()
+	c = probe(&Target{URL: "tcp://" + closed.Addr().String()})
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonConnect, c.reason)
+
+	assert.Error(t, (&Target{URL: "tcp://localhost"}).Validate())
+	assert.Error(t, (&Target{URL: addr, Method: "GET"}).Validate())
+	assert.Error(t, (&Target{URL: "http://localhost", Expect: "PONG"}).Validate())
+}
+
+// dnsServer - локальный dns сервер с фиксированными записями, отвечающий по udp и tcp.
+// На запросы имени big.test по udp отвечает усеченным ответом.
+type dnsServer struct {
+	udp     net.PacketConn
+	tcp     net.Listener
+	mu      sync.Mutex
+	records map[dnsmessage.Question][]dnsmessage.Resource
+}
+
+func newDNSServer(t *testing.T) *dnsServer {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		udp.Close()
+		t.Fatal(err)
+	}
+	s := &dnsServer{udp: udp, tcp: tcp, records: make(map[dnsmessage.Question][]dnsmessage.Resource)}
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := udp.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			udp.WriteTo(s.answer(buf[:n], true), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcp.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 514)
+			if _, err := io.ReadFull(conn, buf[:2]); err == nil {
+				n := int(binary.BigEndian.Uint16(buf))
+				if _, err := io.ReadFull(conn, buf[:n]); err == nil {
+					resp := s.answer(buf[:n], false)
+					conn.Write(append([]byte{byte(len(resp) >> 8), byte(len(resp))}, resp...))
+				}
+			}
+			conn.Close()
+		}
+	}()
+	return s
+}
+
+func (s *dnsServer) add(name string, body dnsmessage.ResourceBody) {
+	q := dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: typeOf(body), Class: dnsmessage.ClassINET}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[q] = append(s.records[q], dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+		Body:   body,
+	})
+}
+
+func typeOf(body dnsmessage.ResourceBody) dnsmessage.Type {
+	switch body.(type) {
+	case *dnsmessage.AResource:
+		return dnsmessage.TypeA
+	case *dnsmessage.AAAAResource:
+		return dnsmessage.TypeAAAA
+	case *dnsmessage.CNAMEResource:
+		return dnsmessage.TypeCNAME
+	case *dnsmessage.MXResource:
+		return dnsmessage.TypeMX
+	}
+	return dnsmessage.TypeTXT
+}
+
+func (s *dnsServer) answer(req []byte, udp bool) []byte {
+	var m dnsmessage.Message
+	if err := m.Unpack(req); err != nil || len(m.Questions) != 1 {
+		return nil
+	}
+	q := m.Questions[0]
+	m.Response = true
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if udp && q.Name.String() == "big.test." {
+		m.Truncated = true
+	} else if answers, ok := s.records[q]; ok {
+		m.Answers = answers
+	} else {
+		m.RCode = dnsmessage.RCodeNameError
+	}
+	resp, _ := m.Pack()
+	return resp
+}
+
+func (s *dnsServer) Close() {
+	s.udp.Close()
+	s.tcp.Close()
+}
+
+func TestProbeDNS(t *testing.T) {
+	srv := newDNSServer(t)
+	defer srv.Close()
+	srv.add("example.test.", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})
+	srv.add("example.test.", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}})
+	srv.add("example.test.", &dnsmessage.AAAAResource{AAAA: [16]byte{15: 1}})
+	srv.add("www.example.test.", &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("example.test.")})
+	srv.add("example.test.", &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mx.example.test.")})
+	srv.add("example.test.", &dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}})
+	srv.add("big.test.", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 3}})
+	resolver := "dns://" + srv.udp.LocalAddr().String()
+
+	p := newTestPoller()
+	p.Timeout = time.Second
+	probe := func(target *Target) *call {
+		if !assert.Nil(t, target.Validate()) {
+			return new(call)
+		}
+		return p.probe(context.Background(), target)
+	}
+
+	c := probe(&Target{URL: resolver + "/example.test", Values: []string{"10.0.0.2"}})
+	assert.True(t, c.isAvailable, c.err)
+	assert.True(t, c.latency > 0)
+	assert.Equal(t, c.latency, c.phases.DNS)
+
+	for _, target := range []*Target{
+		{URL: resolver + "/example.test", Record: "aaaa", Values: []string{"::1"}},
+		{URL: resolver + "/www.example.test", Record: RecordCNAME, Values: []string{"Example.Test."}},
+		{URL: resolver + "/example.test", Record: RecordMX, Values: []string{"mx.example.test"}},
+		{URL: resolver + "/example.test", Record: RecordTXT, Values: []string{"v=spf1 -all"}},
+		{URL: resolver + "/big.test", Values: []string{"10.0.0.3"}}, // повтор запроса по tcp
+	} {
+		c = probe(target)
+		assert.True(t, c.isAvailable, "%s %s: %s", target.URL, target.Record, c.err)
+	}
+
+	// несовпадение сохраняется вместе с полученным ответом
+	c = probe(&Target{URL: resolver + "/example.test", Values: []string{"10.0.0.1", "10.0.0.9"}})
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonBodyMismatch, c.reason)
+	assert.Equal(t, "A 10.0.0.9", c.check)
+	assert.Equal(t, "observed 10.0.0.1, 10.0.0.2", c.err)
+
+	c = probe(&Target{URL: resolver + "/missing.test"})
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonDNS, c.reason)
+
+	c = probe(&Target{URL: resolver + "/www.example.test", Record: RecordMX})
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonDNS, c.reason)
+
+	assert.Error(t, (&Target{URL: "dns:///example.test"}).Validate())
+	assert.Error(t, (&Target{URL: resolver}).Validate())
+	assert.Error(t, (&Target{URL: resolver + "/example.test", Record: "SRV"}).Validate())
+	assert.Error(t, (&Target{URL: resolver + "/example.test", Values: []string{"::1"}}).Validate())
+	assert.Error(t, (&Target{URL: resolver + "/example.test", Expect: "x"}).Validate())
+}
+
+func TestProbeCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	p := &Poller{RootCAs: roots}
+	p.client = newHTTPClient(p.RootCAs)
+	target := &Target{URL: srv.URL}
+	assert.Nil(t, target.Validate())
+	c := p.probe(context.Background(), target)
+	assert.True(t, c.isAvailable, c.err)
+	if assert.NotNil(t, c.cert) && assert.Len(t, c.cert.Chain, 1) {
+		assert.Equal(t, "O=Acme Co", c.cert.Chain[0].Subject)
+		assert.Contains(t, c.cert.Chain[0].DNSNames, "example.com")
+		assert.Equal(t, "SHA256-RSA", c.cert.Chain[0].SignatureAlgorithm)
+		assert.Empty(t, c.cert.Error)
+		assert.Empty(t, c.cert.Warnings)
+	}
+
+	// до окончания действия сертификата тестового сервера меньше 100 лет
+	p.CertWarnDays = 100 * 365
+	c = p.probe(context.Background(), target)
+	if assert.NotNil(t, c.cert) && assert.Len(t, c.cert.Warnings, 1) {
+		assert.Contains(t, c.cert.Warnings[0], "certificate expires in")
+	}
+
+	// имя не совпадает с сертификатом, цепочка сохраняется вместе с ошибкой
+	p.CertWarnDays = 0
+	target = &Target{URL: "https://localhost:" + u.Port()}
+	assert.Nil(t, target.Validate())
+	c = p.probe(context.Background(), target)
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonTLS, c.reason)
+	if assert.NotNil(t, c.cert) && assert.Len(t, c.cert.Chain, 1) {
+		assert.Contains(t, c.cert.Error, "localhost")
+		assert.Len(t, c.cert.Warnings, 1)
+	}
+
+	// недоверенный сертификат
+	p = newTestPoller()
+	c = p.probe(context.Background(), &Target{URL: srv.URL, Method: http.MethodHead, Status: defaultStatus})
+	assert.Equal(t, storage.ReasonTLS, c.reason)
+	if assert.NotNil(t, c.cert) {
+		assert.Contains(t, c.cert.Error, "unknown authority")
+	}
+
+	// RootCAs используется и при запуске поллера
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+	p = &Poller{URLs: []string{srv.URL}, Interval: time.Hour, Timeout: time.Second, Workers: 1, DB: db,
+		Spread: SpreadNone, RootCAs: roots}
+	assert.Nil(t, p.Start(context.Background()))
+	time.Sleep(200 * time.Millisecond)
+	assert.Nil(t, p.Stop())
+	res, err := db.GetLastResult(srv.URL)
+	if assert.Nil(t, err) {
+		assert.Empty(t, res.Reason, res.Error)
+	}
+	info, err := db.GetCertificate(srv.URL)
+	if assert.Nil(t, err) {
+		assert.Empty(t, info.Error)
+	}
+}
+
+func TestProbeGRPC(t *testing.T) {
+	// сертификат и доверяющий ему клиент берутся у тестового https сервера
+	https := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	cert := https.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(https.Certificate())
+	https.Close()
+
+	health := healthpkg.NewServer()
+	health.SetServingStatus("up", healthpb.HealthCheckResponse_SERVING)
+	health.SetServingStatus("down", healthpb.HealthCheckResponse_NOT_SERVING)
+	start := func(opts ...grpc.ServerOption) (string, func()) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := grpc.NewServer(opts...)
+		healthpb.RegisterHealthServer(srv, health)
+		go srv.Serve(ln)
+		return ln.Addr().String(), srv.Stop
+	}
+	addr, stop := start()
+	defer stop()
+	tlsAddr, stopTLS := start(grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+	defer stopTLS()
+
+	p := newTestPoller()
+	p.Timeout = time.Second
+	probe := func(url string) *call {
+		target := &Target{URL: url}
+		if !assert.Nil(t, target.Validate()) {
+			return new(call)
+		}
+		return p.probe(context.Background(), target)
+	}
+
+	for _, url := range []string{"grpc://" + addr, "grpc://" + addr + "/up"} {
+		c := probe(url)
+		assert.True(t, c.isAvailable, "%s: %s", url, c.err)
+		assert.True(t, c.latency > 0)
+		assert.Zero(t, c.status)
+		assert.Nil(t, c.cert)
+	}
+
+	c := probe("grpc://" + addr + "/down")
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonNotServing, c.reason)
+	assert.Equal(t, "NOT_SERVING", c.err)
+
+	c = probe("grpc://" + addr + "/unknown")
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonNotServing, c.reason)
+
+	// tls без доверенного сертификата
+	c = probe("grpcs://" + tlsAddr + "/up")
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonTLS, c.reason, c.err)
+	if assert.NotNil(t, c.cert) {
+		assert.Contains(t, c.cert.Error, "unknown authority")
+	}
+
+	p.RootCAs = roots
+	c = probe("grpcs://" + tlsAddr + "/up")
+	assert.True(t, c.isAvailable, c.err)
+	if assert.NotNil(t, c.cert) {
+		assert.Empty(t, c.cert.Error)
+	}
+
+	stop()
+	c = probe("grpc://" + addr)
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, storage.ReasonConnect, c.reason)
+
+	assert.Error(t, (&Target{URL: "grpc://localhost"}).Validate())
+	assert.Error(t, (&Target{URL: "grpc://localhost:1/a/b"}).Validate())
+	assert.Error(t, (&Target{URL: "grpc://localhost:1", Method: "GET"}).Validate())
+}
+
+func TestProbeStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p := newTestPoller()
+	target := &Target{URL: srv.URL}
+	assert.Nil(t, target.Validate())
+
+	c := p.probe(context.Background(), target)
+	assert.False(t, c.isAvailable)
+	assert.Equal(t, http.StatusServiceUnavailable, c.status)
+	assert.Equal(t, storage.ReasonHTTPStatus, c.reason)
+	assert.Equal(t, "status 200-399", c.check)
+
+	target = &Target{URL: srv.URL, Status: []StatusRange{{200, 299}, {503, 503}}}
+	assert.Nil(t, target.Validate())
+	c = p.probe(context.Background(), target)
+	assert.True(t, c.isAvailable)
+}
+
+func TestProbeBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Write([]byte(`<h1>Maintenance</h1>`))
+			return
+		}
+		w.Write([]byte(`{"status": "up", "items": [{"id": 1}], "total": 1000000, "ratio": 0.25}`))
+	}))
+	defer srv.Close()
+
+	p := newTestPoller()
+	tests := []struct {
+		target    Target
+		available bool
+		check     string
+	}{
+		{Target{URL: srv.URL, Method: "get", Checks: []Check{{Type: CheckContains, Value: "Maintenance"}}}, true, ""},
+		{Target{URL: srv.URL, Method: "GET", Checks: []Check{{Type: CheckRegex, Value: `^\{`}}}, false, `regex "^\\{"`},
+		{Target{URL: srv.URL, Method: "POST", Body: "{}", Checks: []Check{{Type: CheckJSON, Path: "status", Value: "up"}}}, true, ""},
+		{Target{URL: srv.URL, Method: "POST", Checks: []Check{{Type: CheckJSON, Path: "items.0.id", Value: "1"}}}, true, ""},
+		{Target{URL: srv.URL, Method: "POST", Checks: []Check{{Type: CheckJSON, Path: "items.1.id", Value: "1"}}}, false, `json items.1.id == "1"`},
+		{Target{URL: srv.URL, Method: "POST", Checks: []Check{{Type: CheckJSON, Path: "total", Value: "1000000"}}}, true, ""},
+		{Target{URL: srv.URL, Method: "POST", Checks: []Check{{Type: CheckJSON, Path: "ratio", Value: "0.25"}}}, true, ""},
+	}
+	for _, tt := range tests {
+		target := tt.target
+		assert.Nil(t, target.Validate())
+		c := p.probe(context.Background(), &target)
+		assert.Equal(t, tt.available, c.isAvailable, target.URL)
+		assert.Equal(t, tt.check, c.check)
+		if !tt.available {
+			assert.Equal(t, storage.ReasonBodyMismatch, c.reason)
+		}
+	}
+}
+
+func TestProbeFailureReason(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer slow.Close()
+
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsSrv.Close()
+
+	// свободный порт, на котором никто не слушает
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	closed := "http://" + lis.Addr().String()
+	lis.Close()
+
+	p := &Poller{client: http.Client{Timeout: 500 * time.Millisecond}}
+	tests := []struct {
+		url    string
+		reason string
+	}{
+		{slow.URL, storage.ReasonTimeout},
+		{tlsSrv.URL, storage.ReasonTLS},
+		{closed, storage.ReasonConnect},
+	}
+	for _, tt := range tests {
+		target := &Target{URL: tt.url}
+		assert.Nil(t, target.Validate())
+		c := p.probe(context.Background(), target)
+		assert.False(t, c.isAvailable)
+		assert.Equal(t, tt.reason, c.reason, tt.url)
+		assert.NotEmpty(t, c.err)
+	}
+
+	dnsErr := &url.Error{Op: "Head", URL: "https://notexist.eu", Err: &net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: &net.DNSError{Err: "no such host", Name: "notexist.eu"},
+	}}
+	assert.Equal(t, storage.ReasonDNS, classifyError(dnsErr))
+}
+
+func TestProbePhases(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	p := &Poller{RootCAs: roots}
+	p.client = newHTTPClient(p.RootCAs)
+	target := &Target{URL: srv.URL, Method: "GET"}
+	assert.Nil(t, target.Validate())
+
+	// клиент поллера подключается заново на каждую проверку, поэтому этапы
+	// подключения есть и у повторной проверки того же ресурса
+	for i := 0; i < 2; i++ {
+		c := p.probe(context.Background(), target)
+		assert.True(t, c.isAvailable)
+		if assert.NotNil(t, c.phases) {
+			assert.True(t, c.phases.Connect > 0, "probe %d: %+v", i, c.phases)
+			assert.True(t, c.phases.TLS > 0, "probe %d: %+v", i, c.phases)
+			assert.True(t, c.phases.TTFB >= int64(10*time.Millisecond))
+			assert.True(t, c.latency >= c.phases.Connect+c.phases.TLS+c.phases.TTFB)
+		}
+	}
+
+	// параллельные подключения по нескольким адресам, учитывается первое успешное
+	pt := new(phaseTimer)
+	trace := pt.trace()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			trace.ConnectStart("tcp", "")
+			var err error
+			if i == 1 {
+				err = errors.New("connection refused")
+			}
+			trace.ConnectDone("tcp", "", err)
+		}(i)
+	}
+	wg.Wait()
+	assert.True(t, pt.phases().Connect > 0)
+}
+
+func TestIncidentTracker(t *testing.T) {
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+
+	tracker, err := newIncidentTracker(db, 3, 2)
+	assert.Nil(t, err)
+
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	probe := func(i int, available bool) {
+		c := &call{url: "https://ya.ru", time: start.Add(time.Duration(i) * time.Minute), isAvailable: available}
+		if !available {
+			c.reason = storage.ReasonConnect
+		}
+		assert.Nil(t, tracker.track(c))
+	}
+	list := func() []storage.Incident {
+		incidents, err := db.ListIncidents("https://ya.ru", time.Time{}, start.Add(time.Hour))
+		assert.Nil(t, err)
+		return incidents
+	}
+
+	// единичные отказы не открывают инцидент
+	probe(0, false)
+	probe(1, false)
+	probe(2, true)
+	assert.Empty(t, list())
+
+	probe(3, false)
+	probe(4, false)
+	probe(5, false)
+	if incidents := list(); assert.Len(t, incidents, 1) {
+		assert.True(t, incidents[0].IsOpen())
+		assert.Equal(t, start.Add(3*time.Minute), incidents[0].Start.UTC())
+		assert.Equal(t, storage.ReasonConnect, incidents[0].Reason)
+	}
+
+	// одной успешной проверки недостаточно для закрытия
+	probe(6, false)
+	probe(7, true)
+	probe(8, false)
+	probe(9, true)
+	assert.True(t, list()[0].IsOpen())
+
+	// открытый инцидент восстанавливается после перезапуска
+	tracker, err = newIncidentTracker(db, 3, 2)
+	assert.Nil(t, err)
+	probe(10, true)
+	probe(11, true)
+	if incidents := list(); assert.Len(t, incidents, 1) {
+		assert.False(t, incidents[0].IsOpen())
+		assert.Equal(t, 7*time.Minute, incidents[0].Duration(time.Now()))
+	}
+}
+
+func TestTargetValidate(t *testing.T) {
+	bad := []Target{
+		{URL: "ftp://ya.ru"},
+		{URL: "https://ya.ru", Method: "DELETE"},
+		{URL: "https://ya.ru", Body: "data"},
+		{URL: "https://ya.ru", Checks: []Check{{Type: CheckContains, Value: "ok"}}}, // HEAD без тела
+		{URL: "https://ya.ru", Method: "GET", Checks: []Check{{Type: CheckRegex, Value: "("}}},
+		{URL: "https://ya.ru", Status: []StatusRange{{300, 200}}},
+	}
+	for _, target := range bad {
+		assert.Error(t, target.Validate(), "%+v", target)
+	}
+}
+
+func TestFlapDetector(t *testing.T) {
+	d := newFlapDetector(25, 50)
+	track := func(available bool) (bool, float64) {
+		return d.track(&call{url: "https://ya.ru", isAvailable: available})
+	}
+
+	// стабильный ресурс
+	for i := 0; i < flapHistory; i++ {
+		flapping, pct := track(true)
+		assert.False(t, flapping)
+		assert.Zero(t, pct)
+	}
+
+	// ресурс меняет состояние при каждой проверке
+	flapping := false
+	for i := 0; i < 12 && !flapping; i++ {
+		flapping, _ = track(i%2 == 1)
+	}
+	assert.True(t, flapping)
+
+	// стабилизация с гистерезисом: процент сначала падает ниже high, но не ниже low
+	flapping, pct := track(true)
+	assert.True(t, flapping)
+	for i := 0; i < flapHistory && flapping; i++ {
+		flapping, pct = track(true)
+	}
+	assert.False(t, flapping)
+	assert.True(t, pct < 25)
+
+	// полная история смен состояния - 100%
+	assert.InDelta(t, 100, stateChange([]bool{
+		true, false, true, false, true, false, true, false, true, false, true,
+		false, true, false, true, false, true, false, true, false, true,
+	}), 0.001)
+}
+
+func TestDynamicTargets(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hits = make(map[string]int)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	count := func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[path]
+	}
+
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+
+	p := &Poller{
+		URLs:     []string{srv.URL + "/a", srv.URL + "/a"},
+		Interval: 20 * time.Millisecond,
+		Timeout:  time.Second,
+		Workers:  2,
+		DB:       db,
+	}
+	assert.Len(t, p.ActiveTargets(), 1, "duplicates are skipped")
+
+	assert.Error(t, p.AddTarget(Target{URL: srv.URL + "/a"}))
+	assert.Error(t, p.AddTarget(Target{URL: "ftp://" + srv.URL}))
+	assert.Nil(t, p.AddTarget(Target{URL: srv.URL + "/b"}))
+	assert.Error(t, p.UpdateTarget(Target{URL: srv.URL + "/c"}))
+	assert.Error(t, p.RemoveTarget(srv.URL+"/c"))
+
+	assert.Nil(t, p.Start(context.Background()))
+	defer p.Stop()
+	waitFor := func(path string) {
+		deadline := time.Now().Add(2 * time.Second)
+		for count(path) == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.NotZero(t, count(path), path)
+	}
+	waitFor("/a")
+	waitFor("/b")
+
+	// изменения применяются к работающему поллеру
+	assert.Nil(t, p.UpdateTarget(Target{URL: srv.URL + "/b", Method: "GET"}))
+	assert.Nil(t, p.RemoveTarget(srv.URL+"/a"))
+	assert.Nil(t, p.AddTarget(Target{URL: srv.URL + "/c"}))
+	waitFor("/c")
+	time.Sleep(3 * p.Interval)
+	removed := count("/a")
+	time.Sleep(5 * p.Interval)
+	assert.Equal(t, removed, count("/a"))
+
+	targets := p.ActiveTargets()
+	if assert.Len(t, targets, 2) {
+		assert.Equal(t, srv.URL+"/b", targets[0].URL)
+		assert.Equal(t, "GET", targets[0].Method)
+		assert.Equal(t, srv.URL+"/c", targets[1].URL)
+	}
+}
+
+func TestSetTargets(t *testing.T) {
+	p := &Poller{URLs: []string{"https://a.ru", "https://b.ru", "https://c.ru"}}
+
+	diff, err := p.SetTargets([]Target{{URL: "https://a.ru"}, {URL: "ftp://d.ru"}})
+	assert.Error(t, err)
+	assert.True(t, diff.Empty())
+	assert.Len(t, p.ActiveTargets(), 3, "targets are not changed on error")
+
+	_, err = p.SetTargets([]Target{{URL: "https://a.ru"}, {URL: "https://a.ru"}})
+	assert.Error(t, err)
+
+	diff, err = p.SetTargets([]Target{
+		{URL: "https://a.ru"},
+		{URL: "https://b.ru", Method: "GET", Checks: []Check{{Type: CheckRegex, Value: "ok"}}},
+		{URL: "https://d.ru"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, TargetsDiff{
+		Added:   []string{"https://d.ru"},
+		Updated: []string{"https://b.ru"},
+		Removed: []string{"https://c.ru"},
+	}, diff)
+
+	// повторное применение тех же настроек ничего не меняет
+	diff, err = p.SetTargets([]Target{
+		{URL: "https://a.ru"},
+		{URL: "https://b.ru", Method: "GET", Checks: []Check{{Type: CheckRegex, Value: "ok"}}},
+		{URL: "https://d.ru"},
+	})
+	assert.Nil(t, err)
+	assert.True(t, diff.Empty(), "%v", diff)
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	// без положительного периода планировщик не может рассчитать время опросов
+	for _, interval := range []time.Duration{0, -time.Second} {
+		p := &Poller{URLs: []string{"https://ya.ru"}, Interval: interval, Workers: 1}
+		done := make(chan error, 1)
+		go func() { done <- p.Run(context.Background()) }()
+		select {
+		case err := <-done:
+			assert.Error(t, err, "interval %v", interval)
+		case <-time.After(time.Second):
+			p.Stop()
+			t.Fatalf("interval %v: poller is started", interval)
+		}
+		assert.Error(t, p.Start(context.Background()))
+		assert.Nil(t, p.Stop())
+	}
+
+	assert.Error(t, (&Target{URL: "https://ya.ru", Interval: -time.Second}).Validate())
+}
+
+func TestScheduler(t *testing.T) {
+	fast := &Target{URL: "https://fast.ru", Interval: time.Second}
+	slow := &Target{URL: "https://slow.ru", Interval: 3 * time.Second}
+	def := &Target{URL: "https://default.ru"}
+
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	s := newScheduler(2*time.Second, SpreadNone, 0)
+	s.sync([]*Target{fast, slow, def}, start)
+
+	// число опросов каждого ресурса за 6 секунд
+	counts := make(map[string]int)
+	for now := start; now.Before(start.Add(6 * time.Second)); now = now.Add(s.wait(now)) {
+		for _, j := range s.due(now) {
+			counts[j.target.URL]++
+		}
+	}
+	assert.Equal(t, map[string]int{"https://fast.ru": 6, "https://slow.ru": 2, "https://default.ru": 3}, counts)
+
+	// после задержки пропущенные опросы не наверстываются
+	late := start.Add(time.Minute)
+	assert.Len(t, s.due(late), 3)
+	assert.Empty(t, s.due(late))
+	assert.Equal(t, time.Second, s.wait(late))
+
+	// изменение периода планирует опрос сразу, удаленный ресурс не опрашивается
+	s.sync([]*Target{{URL: "https://fast.ru", Interval: 10 * time.Second}, def}, late)
+	assert.Zero(t, s.wait(late))
+	due := s.due(late)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, 10*time.Second, due[0].target.Interval)
+	}
+	assert.Equal(t, 2*time.Second, s.wait(late))
+}
+
+func TestSchedulerSpread(t *testing.T) {
+	var targets []*Target
+	for i := 0; i < 100; i++ {
+		targets = append(targets, &Target{URL: fmt.Sprintf("https://site%d.ru", i)})
+	}
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	interval := 10 * time.Second
+
+	// смещения не зависят от момента запуска и равномерно распределены по периоду
+	s1 := newScheduler(interval, SpreadHash, 0)
+	s1.sync(targets, start)
+	s2 := newScheduler(interval, "", 0)
+	s2.sync(targets, start.Add(3*interval+time.Millisecond))
+	perSecond := make(map[time.Duration]int)
+	for _, tg := range targets {
+		e1, e2 := s1.entries[tg.URL], s2.entries[tg.URL]
+		offset := e1.next.Sub(start)
+		assert.True(t, offset >= 0 && offset < interval)
+		assert.Equal(t, offset, e2.next.Sub(start)%interval, tg.URL)
+		perSecond[offset.Truncate(time.Second)]++
+	}
+	assert.Len(t, perSecond, 10)
+	for sec, n := range perSecond {
+		assert.True(t, n < 20, "%v: %d targets", sec, n)
+	}
+
+	// случайная задержка не сдвигает расписание
+	s := newScheduler(interval, SpreadNone, time.Second)
+	s.sync(targets[:1], start)
+	e := s.entries[targets[0].URL]
+	for i := 0; i < 10; i++ {
+		assert.True(t, e.next.Sub(e.base) >= 0 && e.next.Sub(e.base) < time.Second)
+		assert.Equal(t, start.Add(time.Duration(i)*interval), e.base)
+		s.due(e.next)
+	}
+}
+
+func TestOverrun(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		active, maxActive int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		time.Sleep(150 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+
+	for _, policy := range []string{OverrunSkip, OverrunQueue} {
+		p := &Poller{
+			URLs:     []string{srv.URL},
+			Interval: 50 * time.Millisecond,
+			Timeout:  time.Second,
+			Workers:  4,
+			DB:       db,
+			Spread:   SpreadNone,
+			Overrun:  policy,
+		}
+		assert.Nil(t, p.Start(context.Background()))
+		time.Sleep(600 * time.Millisecond)
+		assert.Nil(t, p.Stop())
+
+		st := p.Stats()
+		assert.True(t, st.Scheduled >= 5, "%s: %+v", policy, st)
+		if policy == OverrunSkip {
+			assert.NotZero(t, st.Skipped, "%+v", st)
+			assert.Zero(t, st.Queued, "%+v", st)
+		} else {
+			assert.NotZero(t, st.Queued, "%+v", st)
+			assert.NotZero(t, st.Late, "queued probes start late: %+v", st)
+		}
+	}
+
+	// ресурс не опрашивается параллельно
+	mu.Lock()
+	assert.Equal(t, 1, maxActive)
+	mu.Unlock()
+}
+
+func TestOverrunRemovedTarget(t *testing.T) {
+	var requests int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	}))
+	defer srv.Close()
+
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+
+	p := &Poller{
+		URLs:     []string{srv.URL},
+		Interval: 20 * time.Millisecond,
+		Timeout:  time.Second,
+		Workers:  1,
+		DB:       db,
+		Spread:   SpreadNone,
+		Overrun:  OverrunQueue,
+	}
+	assert.Nil(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	// пока идет первый опрос, следующий откладывается
+	<-started
+	time.Sleep(100 * time.Millisecond)
+	assert.NotZero(t, p.Stats().Queued)
+
+	// отложенный опрос удаленного ресурса не выполняется
+	assert.Nil(t, p.RemoveTarget(srv.URL))
+	close(release)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Zero(t, p.Stats().InFlight)
+}
+
+func TestIncidentRemovedTarget(t *testing.T) {
+	// сервер закрыт, все проверки неудачные
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+
+	p := &Poller{
+		URLs:         []string{srv.URL},
+		Interval:     20 * time.Millisecond,
+		Timeout:      time.Second,
+		Workers:      1,
+		DB:           db,
+		IncidentOpen: 1,
+	}
+	assert.Nil(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	list := func() []storage.Incident {
+		incidents, err := db.ListIncidents(srv.URL, time.Time{}, time.Now().Add(time.Hour))
+		assert.Nil(t, err)
+		return incidents
+	}
+	for deadline := time.Now().Add(2 * time.Second); len(list()) == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if incidents := list(); assert.Len(t, incidents, 1) {
+		assert.True(t, incidents[0].IsOpen())
+	}
+
+	// инцидент удаленного ресурса закрывается, состояние ресурса удаляется
+	assert.Nil(t, p.RemoveTarget(srv.URL))
+	time.Sleep(100 * time.Millisecond)
+	if incidents := list(); assert.Len(t, incidents, 1) {
+		assert.False(t, incidents[0].IsOpen())
+	}
+	p.incidents.mu.Lock()
+	assert.NotContains(t, p.incidents.states, srv.URL)
+	p.incidents.mu.Unlock()
+	p.flaps.mu.Lock()
+	assert.NotContains(t, p.flaps.states, srv.URL)
+	p.flaps.mu.Unlock()
+}
+
+func TestLifecycle(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	db := storage.NewBoltStorage("test.db")
+	defer func() {
+		db.Close()
+		os.Remove("test.db")
+	}()
+
+	// остановка до запуска и повторная остановка безопасны
+	p := &Poller{URLs: []string{srv.URL}, Interval: time.Second, Workers: 1, DB: db}
+	assert.Nil(t, p.Stop())
+	assert.Nil(t, p.Stop())
+	assert.Nil(t, p.Run(context.Background()), "stopped poller returns at once")
+	assert.Equal(t, ErrStarted, p.Start(context.Background()))
+
+	// отмена контекста
+	p = &Poller{URLs: []string{srv.URL}, Interval: 20 * time.Millisecond, Workers: 1, DB: db, Spread: SpreadNone}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, p.Run(ctx))
+	assert.NotZero(t, p.Stats().Scheduled)
+	assert.Nil(t, p.Stop())
+
+	// зависший запрос прерывается по истечении ShutdownTimeout
+	p = &Poller{
+		URLs:            []string{srv.URL + "/hang"},
+		Interval:        time.Second,
+		Timeout:         time.Minute,
+		Workers:         1,
+		DB:              db,
+		Spread:          SpreadNone,
+		ShutdownTimeout: 50 * time.Millisecond,
+	}
+	assert.Nil(t, p.Start(context.Background()))
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	assert.Nil(t, p.Stop())
+	assert.True(t, time.Since(start) < time.Second)
+	_, err := db.GetLastResult(srv.URL + "/hang")
+	assert.Error(t, err, "canceled probe is not saved")
+
+	// ошибки записи в БД подряд останавливают поллер
+	closed := storage.NewBoltStorage("closed.db")
+	closed.Close()
+	defer os.Remove("closed.db")
+	p = &Poller{
+		URLs:           []string{srv.URL},
+		Interval:       10 * time.Millisecond,
+		Workers:        1,
+		DB:             closed,
+		Spread:         SpreadNone,
+		MaxWriteErrors: 2,
+	}
+	done := make(chan error, 1)
+	go func() { done <- p.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+		assert.Equal(t, err, p.Stop())
+	case <-time.After(2 * time.Second):
+		t.Fatal("poller is not stopped on write errors")
+	}
+}
@@ -0,0 +1,108 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// Retry - политика повтора неудачной проверки ресурса в рамках одного опроса.
+// Ресурс считается недоступным, только если не удались все попытки.
+// Повторы увеличивают время опроса, поэтому при большом числе попыток стоит
+// учитывать период опроса ресурса (см. Poller.Overrun).
+type Retry struct {
+	Attempts int           // максимальное число попыток, включая первую
+	Backoff  time.Duration // пауза перед первым повтором, удваивается с каждым следующим
+	On       []string      `json:",omitempty"` // причины (storage.Reason*), при которых проверка повторяется, по умолчанию defaultRetryOn
+}
+
+// Ограничения политики повтора: число попыток и пауза между ними, до которой
+// удваивается Backoff, чтобы повторы не растягивали опрос без предела.
+const (
+	maxRetryAttempts = 10
+	maxRetryBackoff  = time.Minute
+)
+
+// defaultRetryOn - причины недоступности, которые чаще всего бывают временными.
+var defaultRetryOn = []string{storage.ReasonDNS, storage.ReasonConnect, storage.ReasonTimeout}
+
+var reasons = map[string]bool{
+	storage.ReasonDNS:          true,
+	storage.ReasonConnect:      true,
+	storage.ReasonTLS:          true,
+	storage.ReasonTimeout:      true,
+	storage.ReasonHTTPStatus:   true,
+	storage.ReasonBodyMismatch: true,
+	storage.ReasonNotServing:   true,
+	storage.ReasonUnknown:      true,
+}
+
+// Validate проверяет настройки политики и заполняет значения по умолчанию.
+func (r *Retry) Validate() error {
+	if r.Attempts < 1 || r.Attempts > maxRetryAttempts {
+		return fmt.Errorf("retry attempts must be in [1, %d]", maxRetryAttempts)
+	}
+	if r.Backoff < 0 {
+		return fmt.Errorf("negative retry backoff")
+	}
+	if len(r.On) == 0 {
+		r.On = defaultRetryOn
+	}
+	for _, reason := range r.On {
+		if !reasons[reason] {
+			return fmt.Errorf("unknown retry reason %q", reason)
+		}
+	}
+	return nil
+}
+
+// retryable возвращает true, если после attempts неудачных попыток
+// по причине reason нужно сделать еще одну.
+func (r *Retry) retryable(reason string, attempts int) bool {
+	if r == nil || attempts >= r.Attempts {
+		return false
+	}
+	for _, on := range r.On {
+		if on == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff возвращает паузу перед попыткой attempt+1, не больше maxRetryBackoff.
+func (r *Retry) backoff(attempt int) time.Duration {
+	d := r.Backoff
+	for i := 1; i < attempt && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d
+}
+
+// probe выполняет проверку ресурса, повторяя ее согласно t.Retry.
+// Время проверки - время начала первой попытки, остальные данные
+// берутся из последней.
+func (p *Poller) probe(ctx context.Context, t *Target) *call {
+	c := p.attempt(ctx, t)
+	c.attempts = 1
+	for !c.isAvailable && !c.canceled && t.Retry.retryable(c.reason, c.attempts) {
+		log.Printf("[INFO] Retry %s after %s (attempt %d)", t.URL, c.reason, c.attempts)
+		timer := time.NewTimer(t.Retry.backoff(c.attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return c // сохраняется результат последней завершенной попытки
+		case <-timer.C:
+		}
+		next := p.attempt(ctx, t)
+		next.time, next.attempts = c.time, c.attempts+1
+		c = next
+	}
+	return c
+}
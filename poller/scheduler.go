@@ -0,0 +1,171 @@
+package poller
+
+import (
+	"container/heap"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// Способы распределения опросов ресурсов по периоду
+const (
+	// SpreadHash - каждый ресурс опрашивается со смещением внутри периода,
+	// которое определяется хешем url и не меняется между перезапусками
+	SpreadHash = "hash"
+	// SpreadNone - новые ресурсы опрашиваются сразу, ресурсы с одинаковым
+	// периодом опрашиваются одновременно
+	SpreadNone = "none"
+)
+
+// scheduler планирует опрос ресурсов, у каждого из которых свой период.
+// Ресурсы хранятся в куче по времени следующего опроса, поэтому выбор
+// очередного ресурса не зависит от их общего числа.
+type scheduler struct {
+	interval time.Duration // период опроса ресурсов без собственного периода
+	spread   string        // SpreadHash или SpreadNone
+	jitter   time.Duration // максимальная случайная задержка опроса
+	rand     *rand.Rand
+	queue    schedQueue
+	entries  map[string]*schedEntry
+}
+
+type schedEntry struct {
+	target *Target
+	base   time.Time // время следующего опроса без случайной задержки
+	next   time.Time // время следующего опроса
+	index  int       // позиция в куче
+}
+
+func newScheduler(interval time.Duration, spread string, jitter time.Duration) *scheduler {
+	switch spread {
+	case "":
+		spread = SpreadHash
+	case SpreadHash, SpreadNone:
+	default:
+		log.Printf("[ERROR] Unknown spread %q, use %q", spread, SpreadHash)
+		spread = SpreadHash
+	}
+	return &scheduler{
+		interval: interval,
+		spread:   spread,
+		jitter:   jitter,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		entries:  make(map[string]*schedEntry),
+	}
+}
+
+// sync приводит очередь в соответствие со списком ресурсов: новые ресурсы
+// планируются согласно способу распределения, удаленные исключаются,
+// у остальных сохраняется время следующего опроса, если не изменился их период.
+func (s *scheduler) sync(targets []*Target, now time.Time) {
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		seen[t.URL] = true
+		e, ok := s.entries[t.URL]
+		if !ok {
+			e = &schedEntry{target: t}
+			s.plan(e, s.first(t, now))
+			s.entries[t.URL] = e
+			heap.Push(&s.queue, e)
+			continue
+		}
+		if t.interval(s.interval) != e.target.interval(s.interval) {
+			s.plan(e, s.first(t, now))
+			heap.Fix(&s.queue, e.index)
+		}
+		e.target = t
+	}
+	for url, e := range s.entries {
+		if !seen[url] {
+			heap.Remove(&s.queue, e.index)
+			delete(s.entries, url)
+		}
+	}
+}
+
+// job - запланированный опрос ресурса.
+type job struct {
+	target *Target
+	at     time.Time // запланированное время опроса
+}
+
+// due возвращает опросы, время которых наступило, и планирует следующие
+// опросы ресурсов. Пропущенные из-за задержки опросы не наверстываются.
+func (s *scheduler) due(now time.Time) []*job {
+	var jobs []*job
+	for len(s.queue) > 0 && !s.queue[0].next.After(now) {
+		e := s.queue[0]
+		jobs = append(jobs, &job{target: e.target, at: e.next})
+		interval := e.target.interval(s.interval)
+		base := e.base.Add(interval)
+		if !base.After(now) {
+			// сохраняем смещение ресурса внутри периода
+			base = base.Add((now.Sub(base)/interval + 1) * interval)
+		}
+		s.plan(e, base)
+		heap.Fix(&s.queue, 0)
+	}
+	return jobs
+}
+
+// first возвращает время первого опроса ресурса, добавленного в момент now.
+func (s *scheduler) first(t *Target, now time.Time) time.Time {
+	if s.spread == SpreadNone {
+		return now
+	}
+	interval := t.interval(s.interval)
+	h := fnv.New64a()
+	h.Write([]byte(t.URL))
+	offset := time.Duration(h.Sum64() % uint64(interval))
+	base := now.Truncate(interval).Add(offset)
+	if base.Before(now) {
+		base = base.Add(interval)
+	}
+	return base
+}
+
+// plan планирует опрос на время base со случайной задержкой до jitter.
+func (s *scheduler) plan(e *schedEntry, base time.Time) {
+	e.base, e.next = base, base
+	if s.jitter > 0 {
+		e.next = base.Add(time.Duration(s.rand.Int63n(int64(s.jitter))))
+	}
+}
+
+// wait возвращает время до следующего опроса.
+func (s *scheduler) wait(now time.Time) time.Duration {
+	if len(s.queue) == 0 {
+		return s.interval
+	}
+	if d := s.queue[0].next.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// schedQueue реализует heap.Interface по времени следующего опроса.
+type schedQueue []*schedEntry
+
+func (q schedQueue) Len() int           { return len(q) }
+func (q schedQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q schedQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *schedQueue) Push(x interface{}) {
+	e := x.(*schedEntry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *schedQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,365 @@
+package poller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы проверок тела ответа
+const (
+	CheckContains = "contains" // тело содержит подстроку Value
+	CheckRegex    = "regex"    // тело соответствует регулярному выражению Value
+	CheckJSON     = "json"     // значение поля Path в json теле равно Value
+)
+
+// Виды ресурсов, определяются схемой url
+const (
+	KindHTTP = "http" // http:// и https://, запрос и проверка ответа
+	KindTCP  = "tcp"  // tcp://host:port, установка соединения и обмен приветствием
+	KindDNS  = "dns"  // dns://resolver[:port]/name, запрос записей у резолвера
+	KindGRPC = "grpc" // grpc://host:port/service и grpcs:// с tls, проверка grpc.health.v1
+)
+
+// Типы записей dns ресурсов
+const (
+	RecordA     = "A"
+	RecordAAAA  = "AAAA"
+	RecordCNAME = "CNAME"
+	RecordMX    = "MX"
+	RecordTXT   = "TXT"
+)
+
+// Target - ресурс для опроса и правила проверки его ответа.
+// Method, Headers, Body, Status и Checks относятся только к http ресурсам,
+// Send и Expect - только к tcp, Record и Values - только к dns.
+type Target struct {
+	URL     string
+	Method  string            // HEAD, GET или POST, по умолчанию HEAD
+	Headers map[string]string `json:",omitempty"` // заголовки запроса
+	Body    string            // тело запроса для POST
+	Status  []StatusRange     // допустимые коды ответа, по умолчанию 200-399
+	Checks  []Check           // проверки тела ответа
+	Tags    []string          `json:",omitempty"` // произвольные метки ресурса
+
+	Interval time.Duration `json:",omitempty"` // период опроса, 0 - Poller.Interval
+	Timeout  time.Duration `json:",omitempty"` // время ожидания ответа, 0 - Poller.Timeout
+
+	Retry *Retry `json:",omitempty"` // повтор неудачной проверки, nil - без повторов
+
+	Send   string `json:",omitempty"` // данные, отправляемые после установки tcp соединения
+	Expect string `json:",omitempty"` // подстрока, которую должен содержать ответ или приветствие сервера
+
+	Record string   `json:",omitempty"` // тип запрашиваемых dns записей, по умолчанию A
+	Values []string `json:",omitempty"` // значения, которые должны быть в ответе, например ip для A
+}
+
+// StatusRange - диапазон кодов ответа [Min, Max].
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// Check - проверка тела ответа.
+type Check struct {
+	Type  string // CheckContains, CheckRegex или CheckJSON
+	Path  string // путь к полю через точку, например "data.items.0.status", только для CheckJSON
+	Value string
+
+	re *regexp.Regexp
+}
+
+var defaultStatus = []StatusRange{{200, 399}}
+
+// Kind возвращает вид ресурса по схеме url или пустую строку для
+// неподдерживаемой схемы.
+func (t *Target) Kind() string {
+	switch {
+	case strings.HasPrefix(t.URL, "http://"), strings.HasPrefix(t.URL, "https://"):
+		return KindHTTP
+	case strings.HasPrefix(t.URL, "tcp://"):
+		return KindTCP
+	case strings.HasPrefix(t.URL, "dns://"):
+		return KindDNS
+	case strings.HasPrefix(t.URL, "grpc://"), strings.HasPrefix(t.URL, "grpcs://"):
+		return KindGRPC
+	}
+	return ""
+}
+
+// Validate проверяет настройки ресурса и заполняет значения по умолчанию.
+func (t *Target) Validate() error {
+	if t.Interval < 0 || t.Timeout < 0 {
+		return fmt.Errorf("%s: negative interval or timeout", t.URL)
+	}
+	if t.Retry != nil {
+		if err := t.Retry.Validate(); err != nil {
+			return fmt.Errorf("%s: %v", t.URL, err)
+		}
+	}
+
+	kind := t.Kind()
+	if kind == "" {
+		return fmt.Errorf("%s: unsupported url scheme", t.URL)
+	}
+	if kind != KindHTTP && (t.Method != "" || len(t.Headers) > 0 || t.Body != "" || len(t.Status) > 0 || len(t.Checks) > 0) {
+		return fmt.Errorf("%s: method, headers, body, status and checks are allowed only for http", t.URL)
+	}
+	if kind != KindTCP && (t.Send != "" || t.Expect != "") {
+		return fmt.Errorf("%s: send and expect are allowed only for tcp", t.URL)
+	}
+	if kind != KindDNS && (t.Record != "" || len(t.Values) > 0) {
+		return fmt.Errorf("%s: record and values are allowed only for dns", t.URL)
+	}
+
+	switch kind {
+	case KindTCP:
+		return t.validateTCP()
+	case KindDNS:
+		return t.validateDNS()
+	case KindGRPC:
+		return t.validateGRPC()
+	}
+	return t.validateHTTP()
+}
+
+func (t *Target) validateHTTP() error {
+	t.Method = strings.ToUpper(t.Method)
+	switch t.Method {
+	case "":
+		t.Method = http.MethodHead
+	case http.MethodHead, http.MethodGet, http.MethodPost:
+	default:
+		return fmt.Errorf("%s: unsupported method %s", t.URL, t.Method)
+	}
+	if t.Body != "" && t.Method != http.MethodPost {
+		return fmt.Errorf("%s: body is allowed only for POST", t.URL)
+	}
+
+	if len(t.Status) == 0 {
+		t.Status = defaultStatus
+	}
+	for _, r := range t.Status {
+		if r.Min > r.Max {
+			return fmt.Errorf("%s: bad status range %s", t.URL, r)
+		}
+	}
+
+	if len(t.Checks) > 0 && t.Method == http.MethodHead {
+		return fmt.Errorf("%s: body checks require GET or POST method", t.URL)
+	}
+	for i := range t.Checks {
+		if err := t.Checks[i].Validate(); err != nil {
+			return fmt.Errorf("%s: %v", t.URL, err)
+		}
+	}
+	return nil
+}
+
+func (t *Target) validateTCP() error {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return fmt.Errorf("%s: %v", t.URL, err)
+	}
+	if u.Hostname() == "" || u.Port() == "" || (u.Path != "" && u.Path != "/") {
+		return fmt.Errorf("%s: expected tcp://host:port", t.URL)
+	}
+	return nil
+}
+
+func (t *Target) validateDNS() error {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return fmt.Errorf("%s: %v", t.URL, err)
+	}
+	name := strings.Trim(u.Path, "/")
+	if u.Hostname() == "" || name == "" || strings.Contains(name, "/") || u.RawQuery != "" {
+		return fmt.Errorf("%s: expected dns://resolver[:port]/name", t.URL)
+	}
+
+	t.Record = strings.ToUpper(t.Record)
+	switch t.Record {
+	case "":
+		t.Record = RecordA
+	case RecordA, RecordAAAA, RecordCNAME, RecordMX, RecordTXT:
+	default:
+		return fmt.Errorf("%s: unsupported record type %s", t.URL, t.Record)
+	}
+	for _, v := range t.Values {
+		ip := net.ParseIP(v)
+		if (t.Record == RecordA && (ip == nil || ip.To4() == nil)) ||
+			(t.Record == RecordAAAA && (ip == nil || ip.To4() != nil)) {
+			return fmt.Errorf("%s: bad %s value %q", t.URL, t.Record, v)
+		}
+	}
+	return nil
+}
+
+func (t *Target) validateGRPC() error {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return fmt.Errorf("%s: %v", t.URL, err)
+	}
+	service := strings.Trim(u.Path, "/")
+	if u.Hostname() == "" || u.Port() == "" || strings.Contains(service, "/") || u.RawQuery != "" {
+		return fmt.Errorf("%s: expected grpc://host:port/service", t.URL)
+	}
+	return nil
+}
+
+// checkStatus проверяет код ответа и возвращает непройденную проверку.
+func (t *Target) checkStatus(code int) (string, bool) {
+	for _, r := range t.Status {
+		if code >= r.Min && code <= r.Max {
+			return "", true
+		}
+	}
+	ranges := make([]string, len(t.Status))
+	for i, r := range t.Status {
+		ranges[i] = r.String()
+	}
+	return "status " + strings.Join(ranges, ","), false
+}
+
+// checkBody выполняет проверки тела ответа и возвращает первую непройденную.
+func (t *Target) checkBody(body []byte) (string, bool) {
+	for _, c := range t.Checks {
+		if !c.match(body) {
+			return c.String(), false
+		}
+	}
+	return "", true
+}
+
+func (r StatusRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// ParseStatusRange разбирает диапазон кодов вида "200" или "200-299".
+func ParseStatusRange(s string) (StatusRange, error) {
+	var (
+		r   StatusRange
+		err error
+	)
+	parts := strings.SplitN(s, "-", 2)
+	if r.Min, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+		return r, errors.New("bad status range " + s)
+	}
+	r.Max = r.Min
+	if len(parts) == 2 {
+		if r.Max, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+			return r, errors.New("bad status range " + s)
+		}
+	}
+	return r, nil
+}
+
+// Validate проверяет настройки проверки и компилирует регулярное выражение.
+func (c *Check) Validate() error {
+	switch c.Type {
+	case CheckContains:
+	case CheckRegex:
+		re, err := regexp.Compile(c.Value)
+		if err != nil {
+			return err
+		}
+		c.re = re
+	case CheckJSON:
+		if c.Path == "" {
+			return errors.New("empty json path")
+		}
+	default:
+		return fmt.Errorf("unknown check type %q", c.Type)
+	}
+	return nil
+}
+
+func (c *Check) String() string {
+	if c.Type == CheckJSON {
+		return fmt.Sprintf("json %s == %q", c.Path, c.Value)
+	}
+	return fmt.Sprintf("%s %q", c.Type, c.Value)
+}
+
+func (c *Check) match(body []byte) bool {
+	switch c.Type {
+	case CheckContains:
+		return strings.Contains(string(body), c.Value)
+	case CheckRegex:
+		return c.re != nil && c.re.Match(body)
+	case CheckJSON:
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return false
+		}
+		v, ok := lookupJSON(v, c.Path)
+		return ok && jsonString(v) == c.Value
+	}
+	return false
+}
+
+// lookupJSON возвращает значение по пути вида "a.b.0.c".
+func lookupJSON(v interface{}, path string) (interface{}, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			val, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			v = val
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// jsonString возвращает значение json в виде строки для сравнения с Check.Value.
+// Числа записываются без экспоненты, как в исходном json.
+func jsonString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// timeout возвращает время ожидания ответа ресурса, def - по умолчанию.
+func (t *Target) timeout(def time.Duration) time.Duration {
+	if t.Timeout > 0 {
+		return t.Timeout
+	}
+	return def
+}
+
+// interval возвращает период опроса ресурса, def - по умолчанию.
+func (t *Target) interval(def time.Duration) time.Duration {
+	if t.Interval > 0 {
+		return t.Interval
+	}
+	return def
+}
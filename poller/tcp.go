@@ -0,0 +1,145 @@
+package poller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// probeTCP устанавливает соединение с ресурсом, отправляет t.Send и ждет
+// в ответе t.Expect. Время отклика - время от начала подключения до получения
+// ожидаемого ответа или до установки соединения, если ответ не ожидается.
+func (p *Poller) probeTCP(ctx context.Context, t *Target) *call {
+	c := new(call)
+	c.url = t.URL
+	c.time = time.Now()
+
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		c.fail(storage.ReasonUnknown, err)
+		return c
+	}
+
+	reqCtx := ctx
+	if timeout := t.timeout(p.Timeout); timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(reqCtx, timeout)
+		defer cancel()
+	}
+	// httptrace замеряет разрешение имени и подключение и без http
+	pt := new(phaseTimer)
+	start := time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(httptrace.WithClientTrace(reqCtx, pt.trace()), "tcp", u.Host)
+	if err != nil {
+		c.canceled = ctx.Err() != nil
+		log.Printf("[WARN] tcp error: %v", err)
+		c.fail(classifyError(err), err)
+		return c
+	}
+	defer conn.Close()
+	defer bindConn(ctx, reqCtx, conn)()
+
+	pt.wroteRequest = time.Now()
+	if t.Send != "" {
+		if _, err := io.WriteString(conn, t.Send); err != nil {
+			c.canceled = ctx.Err() != nil
+			log.Printf("[WARN] tcp error: %v", err)
+			c.fail(classifyError(err), err)
+			return c
+		}
+		pt.wroteRequest = time.Now()
+	}
+	if t.Expect != "" {
+		if err := expect(conn, t.Expect, pt); err != nil {
+			c.canceled = ctx.Err() != nil
+			log.Printf("[WARN] tcp error: %v", err)
+			reason := storage.ReasonBodyMismatch
+			if _, ok := err.(*mismatchError); !ok {
+				reason = classifyError(err)
+			}
+			c.fail(reason, err)
+			c.check = fmt.Sprintf("expect %q", t.Expect)
+			return c
+		}
+	}
+	pt.bodyDone = time.Now()
+
+	c.latency = pt.bodyDone.Sub(start).Nanoseconds()
+	c.phases = pt.phases()
+	c.isAvailable = true
+	return c
+}
+
+// bindConn ограничивает чтение и запись conn дедлайном reqCtx и прерывает
+// их при отмене ctx. Возвращает функцию, которую нужно вызвать по окончании
+// работы с соединением.
+func bindConn(ctx, reqCtx context.Context, conn net.Conn) func() {
+	if deadline, ok := reqCtx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// maxMismatchPrefix - сколько первых полученных байт показывается в ошибке,
+// если ожидаемые данные не получены.
+const maxMismatchPrefix = 64
+
+// mismatchError - ожидаемые данные не получены, got - начало полученных.
+type mismatchError struct {
+	got []byte
+}
+
+func (e *mismatchError) Error() string {
+	if len(e.got) == 0 {
+		return "expected data not received"
+	}
+	got := e.got
+	if len(got) > maxMismatchPrefix {
+		got = got[:maxMismatchPrefix]
+	}
+	return fmt.Sprintf("expected data not received, got %q", got)
+}
+
+// expect читает из conn, пока не встретится подстрока s. Читается не более
+// maxBodySize байт. Если сервер прислал другие данные, то несовпадение
+// возвращается и тогда, когда он не закрыл соединение до дедлайна.
+func expect(conn net.Conn, s string, pt *phaseTimer) error {
+	var (
+		buf  bytes.Buffer
+		data = make([]byte, 4096)
+	)
+	for buf.Len() < maxBodySize {
+		n, err := conn.Read(data)
+		if n > 0 && pt.firstByte.IsZero() {
+			pt.firstByte = time.Now()
+		}
+		buf.Write(data[:n])
+		if bytes.Contains(buf.Bytes(), []byte(s)) {
+			return nil
+		}
+		if err == io.EOF || (err != nil && buf.Len() > 0) {
+			return &mismatchError{got: buf.Bytes()}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return &mismatchError{got: buf.Bytes()}
+}
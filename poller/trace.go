@@ -0,0 +1,72 @@
+package poller
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/akosourov/monitoring/storage"
+)
+
+// phaseTimer замеряет время этапов http запроса с помощью httptrace.
+type phaseTimer struct {
+	dnsStart, dnsDone time.Time
+
+	// при подключении по нескольким адресам (happy eyeballs) ConnectStart и
+	// ConnectDone вызываются параллельно и могут прийти после установки
+	// соединения, учитывается первое начало и первое успешное подключение
+	mu                        sync.Mutex
+	connectStart, connectDone time.Time
+
+	tlsStart, tlsDone       time.Time
+	wroteRequest, firstByte time.Time
+	bodyDone                time.Time
+}
+
+func (pt *phaseTimer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { pt.dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { pt.dnsDone = time.Now() },
+		ConnectStart: func(_, _ string) {
+			pt.mu.Lock()
+			defer pt.mu.Unlock()
+			if pt.connectStart.IsZero() {
+				pt.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			pt.mu.Lock()
+			defer pt.mu.Unlock()
+			if err == nil && pt.connectDone.IsZero() {
+				pt.connectDone = time.Now()
+			}
+		},
+		TLSHandshakeStart:    func() { pt.tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { pt.tlsDone = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { pt.wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { pt.firstByte = time.Now() },
+	}
+}
+
+// phases возвращает длительность этапов запроса. Этапы, которые не выполнялись
+// (например, при повторном использовании соединения), равны нулю.
+func (pt *phaseTimer) phases() *storage.Phases {
+	pt.mu.Lock()
+	connect := since(pt.connectStart, pt.connectDone)
+	pt.mu.Unlock()
+	return &storage.Phases{
+		DNS:      since(pt.dnsStart, pt.dnsDone),
+		Connect:  connect,
+		TLS:      since(pt.tlsStart, pt.tlsDone),
+		TTFB:     since(pt.wroteRequest, pt.firstByte),
+		Transfer: since(pt.firstByte, pt.bodyDone),
+	}
+}
+
+func since(start, end time.Time) int64 {
+	if start.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start).Nanoseconds()
+}
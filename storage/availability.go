@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Availability - доступность url за интервал.
+type Availability struct {
+	Total    int64   // число проверок
+	Failures int64   // число проверок, когда ресурс был недоступен
+	Uptime   float64 // доля успешных проверок, %
+	Excluded int64   // число проверок во время обслуживания, не входят в Total
+
+	// Время недоступности считается в предположении, что состояние ресурса
+	// не меняется до следующей проверки.
+	Observed   time.Duration // время, для которого известно состояние ресурса
+	Downtime   time.Duration // время недоступности
+	TimeUptime float64       // доля времени доступности, %
+}
+
+// GetAvailability возвращает доступность url за интервал [from, to).
+// Состояние на начало интервала определяется последней проверкой до него.
+// Проверки во время обслуживания и время до следующей проверки не учитываются.
+// Если все проверки за интервал пришлись на обслуживание, доступность равна 100%.
+func (b *BoltStorage) GetAvailability(url string, from, to time.Time) (*Availability, error) {
+	a := new(Availability)
+	err := b.view(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte(url))
+		if bkt == nil {
+			return errors.New(url + " not exists")
+		}
+		maintenance := tx.Bucket([]byte(maintenanceProbesBucketName)).Bucket([]byte(url))
+		excluded := func(k []byte) bool {
+			return maintenance != nil && maintenance.Get(k) != nil
+		}
+
+		var (
+			prevTs   int64
+			prevDown bool
+			known    bool // известно состояние ресурса после prevTs
+			seen     bool // есть проверки до конца интервала
+		)
+		if !from.IsZero() {
+			c := bkt.Cursor()
+			k, v := seek(c, from)
+			if k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+			if k != nil {
+				prevTs, prevDown, known, seen = from.UnixNano(), decodeInt(v) < 0, !excluded(k), true
+			}
+		}
+
+		end := to.UnixNano()
+		if now := time.Now().UnixNano(); now < end {
+			end = now
+		}
+		c := bkt.Cursor()
+		for k, v := seek(c, from); k != nil && decodeInt(k) < end; k, v = c.Next() {
+			ts, down := decodeInt(k), decodeInt(v) < 0
+			if known {
+				a.observe(ts-prevTs, prevDown)
+			}
+			prevTs, prevDown, known, seen = ts, down, true, true
+			if excluded(k) {
+				a.Excluded++
+				known = false
+				continue
+			}
+			a.Total++
+			if down {
+				a.Failures++
+			}
+		}
+		if !seen {
+			return errors.New(url + " has no data in range")
+		}
+		if known && end > prevTs {
+			a.observe(end-prevTs, prevDown)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.Uptime, a.TimeUptime = 100, 100
+	if a.Total > 0 {
+		a.Uptime = float64(a.Total-a.Failures) / float64(a.Total) * 100
+	}
+	if a.Observed > 0 {
+		a.TimeUptime = float64(a.Observed-a.Downtime) / float64(a.Observed) * 100
+	}
+	return a, nil
+}
+
+func (a *Availability) observe(dur int64, down bool) {
+	a.Observed += time.Duration(dur)
+	if down {
+		a.Downtime += time.Duration(dur)
+	}
+}
+
+// GetURLs возвращает список url, для которых есть сохраненные значения.
+func (b *BoltStorage) GetURLs() ([]string, error) {
+	urls := []string{}
+	err := b.view(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			if !isServiceBucket(string(name)) {
+				urls = append(urls, string(name))
+			}
+			return nil
+		})
+	})
+	return urls, err
+}
@@ -6,11 +6,37 @@
 в котором ключ - timestamp (нс), а значение - время отклика (нс). В случае, если
 сервис недоступен значение будет равно -1.
 
-2. "AvgLatency" - бакет, для хранения среднего (p50) значения времени отклика (нс) сервиса,
+2. "AvgLatency" - бакет, для хранения среднего значения времени отклика (нс) сервиса,
 где	ключ - url сервиса, значение - json вида {Count: int, Sum: int64, Avg: int64},
 	где Count - общее число записей,
 		Sum - сумма времени отклика,
 		Avg - среднеарифметическое время отклика (нс)
+
+3. "Results" - бакет с вложенными бакетами для каждого url сервиса, в которых
+ключ - timestamp (нс), а значение - json с подробным результатом проверки (Result).
+
+4. "Rollups" - бакет с агрегатами времени отклика по уровням: вложенные бакеты
+"1m", "1h" и "1d" (минута, час, день), в каждом из которых вложенный бакет для
+каждого url сервиса, где ключ - timestamp (нс) начала периода, а значение - json
+агрегата (Rollup): число проверок, число отказов, сумма, минимум, максимум и
+гистограмма (Sketch) времени отклика за период. Используется для расчета
+квантилей и запросов истории за длительные интервалы.
+
+5. "Incidents" - бакет с вложенными бакетами для каждого url сервиса, в которых
+ключ - timestamp (нс) начала периода недоступности, а значение - json инцидента (Incident).
+
+6. "Silences" и "Maintenance" - бакеты отключений оповещений (Silence) и окон
+обслуживания (Maintenance), где ключ - id, а значение - json.
+
+7. "MaintenanceProbes" - бакет с вложенными бакетами для каждого url сервиса, в которых
+ключ - timestamp (нс) проверки во время обслуживания, а значение пустое. Такие проверки
+не учитываются при расчете доступности.
+
+8. "Targets" - бакет настроек опроса ресурсов, добавленных через api, где
+ключ - url, а значение - json настроек.
+
+9. "Certificates" - бакет сертификатов, полученных при последней проверке
+https ресурса, где ключ - url, а значение - json CertInfo.
 */
 
 package storage
@@ -19,30 +45,63 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"sync"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
 )
 
-const avgLatencyBucketName = "AvgLatency"
+const (
+	avgLatencyBucketName        = "AvgLatency"
+	resultsBucketName           = "Results"
+	rollupsBucketName           = "Rollups"
+	incidentsBucketName         = "Incidents"
+	silencesBucketName          = "Silences"
+	maintenanceBucketName       = "Maintenance"
+	maintenanceProbesBucketName = "MaintenanceProbes"
+	targetsBucketName           = "Targets"
+	certificatesBucketName      = "Certificates"
+
+	// бакет поминутных гистограмм, замененный агрегатами в "Rollups",
+	// при открытии базы переносится в агрегаты (см. migrateSketches)
+	legacySketchesBucketName = "Sketches"
+)
+
+// serviceBuckets - бакеты верхнего уровня, не относящиеся к какому-либо url.
+var serviceBuckets = []string{avgLatencyBucketName, resultsBucketName, rollupsBucketName, incidentsBucketName,
+	silencesBucketName, maintenanceBucketName, maintenanceProbesBucketName, targetsBucketName, certificatesBucketName}
 
 // BoltStorage реализует интерфейс Storage
 type BoltStorage struct {
 	path string
-	db   *bolt.DB
+
+	mu sync.RWMutex // блокируется на запись при подмене db во время сжатия файла
+	db *bolt.DB
+
+	// изменения, сделанные во время сжатия файла, для повтора в новом файле
+	journalMu  sync.Mutex
+	compacting bool
+	journal    []func(tx *bolt.Tx) error
+
+	stopJanitor chan struct{}
+	janitorWG   sync.WaitGroup
 }
 
 // NewBoltStorage создает файл с базой данной и/или выполняет подключение к нему.
 func NewBoltStorage(path string) *BoltStorage {
-	db, err := bolt.Open(path, 0666, &bolt.Options{Timeout: 3 * time.Second})
+	db, err := openBolt(path)
 	if err != nil {
 		panic(err)
 	}
 
 	// prepare buckets
 	err = db.Update(func(tx *bolt.Tx) error {
-		_, err = tx.CreateBucketIfNotExists([]byte(avgLatencyBucketName))
-		return err
+		for _, name := range serviceBuckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return migrateSketches(tx)
 	})
 	if err != nil {
 		panic(err)
@@ -54,10 +113,51 @@ func NewBoltStorage(path string) *BoltStorage {
 	}
 }
 
+func openBolt(path string) (*bolt.DB, error) {
+	return bolt.Open(path, 0666, &bolt.Options{Timeout: 3 * time.Second})
+}
+
+// Close останавливает фоновую очистку, если она была запущена, и закрывает базу.
 func (b *BoltStorage) Close() error {
+	b.mu.Lock()
+	if b.stopJanitor != nil {
+		close(b.stopJanitor)
+		b.stopJanitor = nil
+	}
+	b.mu.Unlock()
+	b.janitorWG.Wait()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	return b.db.Close()
 }
 
+func (b *BoltStorage) view(fn func(tx *bolt.Tx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.View(fn)
+}
+
+// update выполняет fn в транзакции на запись. Во время сжатия файла fn
+// повторяется в новом файле уже после возврата из update, поэтому fn должна
+// зависеть только от состояния базы и значений, которые после вызова не
+// меняются, и не должна ничего записывать вне транзакции.
+func (b *BoltStorage) update(fn func(tx *bolt.Tx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	// bolt и так выполняет транзакции на запись по одной, journalMu лишь
+	// сохраняет их порядок в журнале
+	b.journalMu.Lock()
+	defer b.journalMu.Unlock()
+	if err := b.db.Update(fn); err != nil {
+		return err
+	}
+	if b.compacting {
+		b.journal = append(b.journal, fn)
+	}
+	return nil
+}
+
 type avgItem struct {
 	Count int64
 	Sum   int64
@@ -65,53 +165,109 @@ type avgItem struct {
 }
 
 func (b *BoltStorage) PutLatency(url string, lat int64) error {
-	return b.db.Update(func(tx *bolt.Tx) error {
-		bnow := make([]byte, 8)
-		binary.BigEndian.PutUint64(bnow, uint64(time.Now().UnixNano()))
-
-		blat := make([]byte, 8)
-		binary.BigEndian.PutUint64(blat, uint64(lat))
+	ts := time.Now().UnixNano()
+	return b.update(func(tx *bolt.Tx) error {
+		return putLatency(tx, url, ts, lat)
+	})
+}
 
-		// если первое обращение, создаем бакет для хранения времени отклика для url
-		latBkt, err := tx.CreateBucketIfNotExists([]byte(url))
+// PutResult сохраняет время отклика, как и PutLatency, и вместе с ним
+// подробный результат проверки.
+func (b *BoltStorage) PutResult(url string, r *Result) error {
+	ts := r.Time.UnixNano()
+	if r.Time.IsZero() {
+		ts = time.Now().UnixNano()
+	}
+	bres, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	lat, maintenance := r.Latency, r.Maintenance
+	return b.update(func(tx *bolt.Tx) error {
+		if err := putLatency(tx, url, ts, lat); err != nil {
+			return err
+		}
+		resBkt, err := tx.Bucket([]byte(resultsBucketName)).CreateBucketIfNotExists([]byte(url))
 		if err != nil {
 			return err
 		}
-
-		if err := latBkt.Put(bnow, blat); err != nil {
+		if err := resBkt.Put(encodeInt(ts), bres); err != nil {
 			return err
 		}
+		if !maintenance {
+			return nil
+		}
+		mBkt, err := tx.Bucket([]byte(maintenanceProbesBucketName)).CreateBucketIfNotExists([]byte(url))
+		if err != nil {
+			return err
+		}
+		return mBkt.Put(encodeInt(ts), []byte{})
+	})
+}
 
-		// reindex avg
-		if lat >= 0 {
-			avgBkt := tx.Bucket([]byte(avgLatencyBucketName))
-			avg := avgBkt.Get([]byte(url))
-			if avg == nil {
-				item := &avgItem{1, lat, lat}
-				bitem, err := json.Marshal(&item)
-				if err != nil {
-					return err
-				}
-				return avgBkt.Put([]byte(url), bitem)
-			}
+func putLatency(tx *bolt.Tx, url string, ts int64, lat int64) error {
+	// если первое обращение, создаем бакет для хранения времени отклика для url
+	latBkt, err := tx.CreateBucketIfNotExists([]byte(url))
+	if err != nil {
+		return err
+	}
 
-			item := avgItem{}
-			err := json.Unmarshal(avg, &item)
-			if err != nil {
-				return err
-			}
-			item.Count++
-			item.Sum += lat
-			item.Avg = item.Sum / item.Count
+	if err := latBkt.Put(encodeInt(ts), encodeInt(lat)); err != nil {
+		return err
+	}
+
+	if err := putRollups(tx, url, ts, lat); err != nil {
+		return err
+	}
 
+	// reindex avg
+	if lat >= 0 {
+		avgBkt := tx.Bucket([]byte(avgLatencyBucketName))
+		avg := avgBkt.Get([]byte(url))
+		if avg == nil {
+			item := &avgItem{1, lat, lat}
 			bitem, err := json.Marshal(&item)
 			if err != nil {
 				return err
 			}
 			return avgBkt.Put([]byte(url), bitem)
 		}
-		return nil
-	})
+
+		item := avgItem{}
+		err := json.Unmarshal(avg, &item)
+		if err != nil {
+			return err
+		}
+		item.Count++
+		item.Sum += lat
+		item.Avg = item.Sum / item.Count
+
+		bitem, err := json.Marshal(&item)
+		if err != nil {
+			return err
+		}
+		return avgBkt.Put([]byte(url), bitem)
+	}
+	return nil
+}
+
+// seek устанавливает курсор на первый ключ-timestamp не меньше from.
+// Нулевое from означает начало бакета.
+func seek(c *bolt.Cursor, from time.Time) ([]byte, []byte) {
+	if from.IsZero() {
+		return c.First()
+	}
+	return c.Seek(encodeInt(from.UnixNano()))
+}
+
+func encodeInt(v int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
+
+func decodeInt(b []byte) int64 {
+	return int64(binary.BigEndian.Uint64(b))
 }
 
 func (b *BoltStorage) GetMaxLatency() (string, int64, error) {
@@ -119,7 +275,7 @@ func (b *BoltStorage) GetMaxLatency() (string, int64, error) {
 		max int64
 		url string
 	)
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket([]byte(avgLatencyBucketName))
 		c := bkt.Cursor()
 		var item avgItem
@@ -143,7 +299,7 @@ func (b *BoltStorage) GetMinLatency() (string, int64, error) {
 		min int64 = -1
 		url string
 	)
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket([]byte(avgLatencyBucketName))
 		c := bkt.Cursor()
 		var item avgItem
@@ -164,7 +320,7 @@ func (b *BoltStorage) GetMinLatency() (string, int64, error) {
 
 func (b *BoltStorage) GetLastLatency(url string) (int64, error) {
 	var last int64
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket([]byte(url))
 		if bkt == nil {
 			return errors.New(url + " not exists")
@@ -174,15 +330,37 @@ func (b *BoltStorage) GetLastLatency(url string) (int64, error) {
 		if bts == nil {
 			return errors.New("Bucket is empty")
 		}
-		last = int64(binary.BigEndian.Uint64(blat))
+		last = decodeInt(blat)
 		return nil
 	})
 	return last, err
 }
 
+// GetLastResult возвращает результат последней проверки url, сохраненный через PutResult.
+func (b *BoltStorage) GetLastResult(url string) (*Result, error) {
+	var res *Result
+	err := b.view(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte(resultsBucketName)).Bucket([]byte(url))
+		if bkt == nil {
+			return errors.New(url + " not exists")
+		}
+		bts, bres := bkt.Cursor().Last()
+		if bts == nil {
+			return errors.New("Bucket is empty")
+		}
+		res = new(Result)
+		if err := json.Unmarshal(bres, res); err != nil {
+			return err
+		}
+		res.Time = time.Unix(0, decodeInt(bts))
+		return nil
+	})
+	return res, err
+}
+
 func (b *BoltStorage) GetAvgLatency(url string) (int64, error) {
 	var avg int64
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket([]byte(avgLatencyBucketName))
 		bitem := bkt.Get([]byte(url))
 		if bitem == nil {
@@ -197,3 +375,141 @@ func (b *BoltStorage) GetAvgLatency(url string) (int64, error) {
 	})
 	return avg, err
 }
+
+// GetLatencyPercentiles возвращает квантили времени отклика url за последний
+// интервал window. Если window равен нулю, то за все время. Точность окна
+// ограничена периодом уровня агрегации, выбранного для окна.
+func (b *BoltStorage) GetLatencyPercentiles(url string, window time.Duration) (*Percentiles, error) {
+	var from time.Time
+	if window > 0 {
+		from = time.Now().Add(-window)
+	}
+	total := new(Rollup)
+	err := b.view(func(tx *bolt.Tx) error {
+		return eachRollup(tx, url, tierForWindow(window), from, time.Now(), func(r *Rollup) error {
+			total.Merge(r)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if total.Sketch == nil {
+		return NewSketch().Percentiles(), nil
+	}
+	return total.Sketch.Percentiles(), nil
+}
+
+// GetLatencyRange возвращает время отклика url за интервал [from, to) в порядке
+// возрастания времени, но не более limit значений. Если limit равен нулю,
+// то возвращаются все значения интервала.
+func (b *BoltStorage) GetLatencyRange(url string, from, to time.Time, limit int) ([]Point, error) {
+	points := []Point{}
+	err := b.view(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte(url))
+		if bkt == nil {
+			return errors.New(url + " not exists")
+		}
+
+		end := to.UnixNano()
+		c := bkt.Cursor()
+		for k, v := seek(c, from); k != nil; k, v = c.Next() {
+			ts := decodeInt(k)
+			if ts >= end || (limit > 0 && len(points) >= limit) {
+				break
+			}
+			points = append(points, Point{Time: time.Unix(0, ts), Latency: decodeInt(v)})
+		}
+		return nil
+	})
+	return points, err
+}
+
+// GetAvgLatencyRange возвращает среднее время отклика url за интервал [from, to).
+// В отличие от GetAvgLatency, среднее считается по исходным значениям интервала.
+func (b *BoltStorage) GetAvgLatencyRange(url string, from, to time.Time) (int64, error) {
+	var avg int64
+	err := b.view(func(tx *bolt.Tx) error {
+		item, err := rangeAvg(tx, url, from, to)
+		if err != nil {
+			return err
+		}
+		if item.Count == 0 {
+			return errors.New(url + " has no data in range")
+		}
+		avg = item.Avg
+		return nil
+	})
+	return avg, err
+}
+
+// GetMaxLatencyRange возвращает url с максимальным средним временем отклика за интервал [from, to).
+func (b *BoltStorage) GetMaxLatencyRange(from, to time.Time) (string, int64, error) {
+	var (
+		max int64
+		url string
+	)
+	err := b.eachRangeAvg(from, to, func(u string, item *avgItem) {
+		if item.Avg > max {
+			max = item.Avg
+			url = u
+		}
+	})
+	return url, max, err
+}
+
+// GetMinLatencyRange возвращает url с минимальным средним временем отклика за интервал [from, to).
+func (b *BoltStorage) GetMinLatencyRange(from, to time.Time) (string, int64, error) {
+	var (
+		min int64 = -1
+		url string
+	)
+	err := b.eachRangeAvg(from, to, func(u string, item *avgItem) {
+		if min == -1 || item.Avg < min {
+			min = item.Avg
+			url = u
+		}
+	})
+	return url, min, err
+}
+
+// eachRangeAvg вызывает fn для каждого url, у которого есть время отклика за интервал [from, to).
+func (b *BoltStorage) eachRangeAvg(from, to time.Time, fn func(url string, item *avgItem)) error {
+	return b.view(func(tx *bolt.Tx) error {
+		// в AvgLatency есть все url, которые хотя бы раз были доступны
+		c := tx.Bucket([]byte(avgLatencyBucketName)).Cursor()
+		for burl, _ := c.First(); burl != nil; burl, _ = c.Next() {
+			item, err := rangeAvg(tx, string(burl), from, to)
+			if err != nil {
+				return err
+			}
+			if item.Count > 0 {
+				fn(string(burl), item)
+			}
+		}
+		return nil
+	})
+}
+
+// rangeAvg считает среднее время отклика url за интервал [from, to),
+// пропуская факты недоступности.
+func rangeAvg(tx *bolt.Tx, url string, from, to time.Time) (*avgItem, error) {
+	bkt := tx.Bucket([]byte(url))
+	if bkt == nil {
+		return nil, errors.New(url + " not exists")
+	}
+
+	item := new(avgItem)
+	end := to.UnixNano()
+	c := bkt.Cursor()
+	for k, v := seek(c, from); k != nil && decodeInt(k) < end; k, v = c.Next() {
+		if lat := decodeInt(v); lat >= 0 {
+			item.Count++
+			item.Sum += lat
+		}
+	}
+	if item.Count > 0 {
+		item.Avg = item.Sum / item.Count
+	}
+	return item, nil
+}
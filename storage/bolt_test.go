@@ -1,10 +1,13 @@
 package storage
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
 )
 
 const dbTestName = "test.db"
@@ -132,6 +135,671 @@ func TestGetAvgLatency(t *testing.T) {
 	assert.Equal(t, int64(400), avg)
 }
 
+func TestGetLastResult(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	_, err := bolt.GetLastResult("https://ya.ru") // not exists
+	assert.Error(t, err)
+
+	err = bolt.PutResult("https://ya.ru", &Result{Latency: 100, Status: 200})
+	assert.Nil(t, err)
+	err = bolt.PutResult("https://ya.ru", &Result{Latency: -1, Status: 503, Check: "status 200-399"})
+	assert.Nil(t, err)
+
+	res, err := bolt.GetLastResult("https://ya.ru")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), res.Latency)
+	assert.Equal(t, 503, res.Status)
+	assert.Equal(t, "status 200-399", res.Check)
+	assert.False(t, res.Time.IsZero())
+
+	// PutResult обновляет и время отклика
+	lat, err := bolt.GetLastLatency("https://ya.ru")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), lat)
+	avg, err := bolt.GetAvgLatency("https://ya.ru")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), avg)
+}
+
+func TestGetLatencyPercentiles(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	_, err := bolt.GetLatencyPercentiles("https://ya.ru", 0) // not exists
+	assert.Error(t, err)
+
+	for i := int64(1); i <= 1000; i++ {
+		err := bolt.PutLatency("https://ya.ru", i*1000)
+		assert.Nil(t, err)
+	}
+	err = bolt.PutLatency("https://ya.ru", -1) // факт недоступности не учитывается
+	assert.Nil(t, err)
+
+	p, err := bolt.GetLatencyPercentiles("https://ya.ru", time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), p.Count)
+	assert.InEpsilon(t, 500000, p.P50, 2*sketchAccuracy)
+	assert.InEpsilon(t, 900000, p.P90, 2*sketchAccuracy)
+	assert.InEpsilon(t, 950000, p.P95, 2*sketchAccuracy)
+	assert.InEpsilon(t, 990000, p.P99, 2*sketchAccuracy)
+}
+
+func TestSketchMerge(t *testing.T) {
+	a, b := NewSketch(), NewSketch()
+	for i := int64(1); i <= 100; i++ {
+		a.Add(i)
+		b.Add(i + 100)
+	}
+	a.Merge(b)
+	assert.Equal(t, int64(200), a.Count)
+	assert.InEpsilon(t, 100, a.Quantile(0.5), 2*sketchAccuracy)
+	assert.InEpsilon(t, 200, a.Quantile(1), 2*sketchAccuracy)
+	assert.Equal(t, int64(0), NewSketch().Quantile(0.5))
+}
+
+func TestGetLatencyRange(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	_, err := bolt.GetLatencyRange("https://ya.ru", time.Time{}, time.Now(), 0) // not exists
+	assert.Error(t, err)
+
+	start := time.Unix(1500000000, 0)
+	for i := 0; i < 10; i++ {
+		r := &Result{Time: start.Add(time.Duration(i) * time.Minute), Latency: int64(i)}
+		if i == 5 {
+			r.Latency = -1
+		}
+		err := bolt.PutResult("https://ya.ru", r)
+		assert.Nil(t, err)
+	}
+
+	points, err := bolt.GetLatencyRange("https://ya.ru", start.Add(2*time.Minute), start.Add(7*time.Minute), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []Point{
+		{start.Add(2 * time.Minute), 2},
+		{start.Add(3 * time.Minute), 3},
+		{start.Add(4 * time.Minute), 4},
+		{start.Add(5 * time.Minute), -1},
+		{start.Add(6 * time.Minute), 6},
+	}, points)
+
+	points, err = bolt.GetLatencyRange("https://ya.ru", start, start.Add(time.Hour), 3)
+	assert.Nil(t, err)
+	assert.Len(t, points, 3)
+	assert.Equal(t, start.Add(2*time.Minute), points[2].Time)
+
+	points, err = bolt.GetLatencyRange("https://ya.ru", start.Add(time.Hour), start.Add(2*time.Hour), 0)
+	assert.Nil(t, err)
+	assert.Empty(t, points)
+}
+
+func TestLatencyRange(t *testing.T) {
+	urls := []string{"https://google.com", "https://notexist.eu"}
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	// месяц назад google был медленным, а сейчас быстрее notexist.eu
+	now := time.Now()
+	monthAgo := now.Add(-30 * 24 * time.Hour)
+	put := func(url string, ts time.Time, lat int64) {
+		err := bolt.PutResult(url, &Result{Time: ts, Latency: lat})
+		assert.Nil(t, err, "PutResult")
+	}
+	put(urls[0], monthAgo, 10000)
+	put(urls[0], monthAgo.Add(time.Minute), 20000)
+	put(urls[1], monthAgo, 300)
+	put(urls[0], now.Add(-3*time.Minute), 100)
+	put(urls[0], now.Add(-2*time.Minute), 300)
+	put(urls[0], now.Add(-time.Minute), -1) // факт недоступности не учитывается
+	put(urls[1], now.Add(-2*time.Minute), 500)
+
+	maxURL, _, err := bolt.GetMaxLatency()
+	assert.Nil(t, err)
+	assert.Equal(t, urls[0], maxURL)
+
+	from, to := now.Add(-time.Hour), now
+	maxURL, maxLat, err := bolt.GetMaxLatencyRange(from, to)
+	assert.Nil(t, err)
+	assert.Equal(t, urls[1], maxURL)
+	assert.Equal(t, int64(500), maxLat)
+
+	minURL, minLat, err := bolt.GetMinLatencyRange(from, to)
+	assert.Nil(t, err)
+	assert.Equal(t, urls[0], minURL)
+	assert.Equal(t, int64(200), minLat)
+
+	avg, err := bolt.GetAvgLatencyRange(urls[0], from, to)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(200), avg)
+
+	avg, err = bolt.GetAvgLatencyRange(urls[0], monthAgo, monthAgo.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(15000), avg)
+
+	// нет данных за интервал
+	_, err = bolt.GetAvgLatencyRange(urls[1], now.Add(-time.Minute), now)
+	assert.Error(t, err)
+	minURL, minLat, err = bolt.GetMinLatencyRange(now.Add(time.Hour), now.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "", minURL)
+	assert.Equal(t, int64(-1), minLat)
+}
+
+func TestPrune(t *testing.T) {
+	urls := []string{"https://google.com", "https://notexist.eu"}
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		ts := now.Add(-time.Duration(10-i) * time.Hour)
+		err := bolt.PutResult(urls[0], &Result{Time: ts, Latency: int64(i)})
+		assert.Nil(t, err)
+		err = bolt.PutResult(urls[1], &Result{Time: ts, Latency: int64(i)})
+		assert.Nil(t, err)
+	}
+
+	// без ограничений ничего не удаляется
+	n, err := bolt.Prune(Retention{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+
+	// по 5 самых старых значений каждого url старше 5.5 часов
+	n, err = bolt.Prune(Retention{MaxAge: 5*time.Hour + 30*time.Minute})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, n)
+
+	points, err := bolt.GetLatencyRange(urls[0], time.Time{}, now, 0)
+	assert.Nil(t, err)
+	assert.Len(t, points, 5)
+	assert.Equal(t, int64(5), points[0].Latency)
+
+	// остается не более 2 последних значений
+	n, err = bolt.Prune(Retention{MaxAge: time.Hour * 24, MaxPoints: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, n)
+
+	points, err = bolt.GetLatencyRange(urls[1], time.Time{}, now, 0)
+	assert.Nil(t, err)
+	assert.Len(t, points, 2)
+	assert.Equal(t, int64(8), points[0].Latency)
+
+	// подробные результаты удаляются вместе со значениями
+	res, err := bolt.GetLastResult(urls[1])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9), res.Latency)
+	_, err = bolt.GetLatencyPercentiles(urls[1], 0)
+	assert.Nil(t, err)
+}
+
+func TestMigrateSketches(t *testing.T) {
+	// база в прежнем формате: поминутные гистограммы в "Sketches", исходные
+	// значения за вторую минуту уже удалены
+	const url = "https://ya.ru"
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	db, err := openBolt(dbTestName)
+	assert.Nil(t, err)
+	err = db.Update(func(tx *bolt.Tx) error {
+		raw, err := tx.CreateBucket([]byte(url))
+		if err != nil {
+			return err
+		}
+		raw.Put(encodeInt(start.UnixNano()), encodeInt(100))
+		raw.Put(encodeInt(start.Add(time.Second).UnixNano()), encodeInt(-1))
+
+		sketches, err := tx.CreateBucket([]byte(legacySketchesBucketName))
+		if err != nil {
+			return err
+		}
+		bkt, err := sketches.CreateBucket([]byte(url))
+		if err != nil {
+			return err
+		}
+		for i, lats := range [][]int64{{100}, {200, 300}} {
+			sk := NewSketch()
+			for _, lat := range lats {
+				sk.Add(lat)
+			}
+			bsk, _ := json.Marshal(sk)
+			bkt.Put(encodeInt(start.Add(time.Duration(i)*time.Minute).UnixNano()), bsk)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	storage := NewBoltStorage(dbTestName)
+	defer cleanup(storage, t)
+
+	urls, err := storage.GetURLs()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{url}, urls)
+
+	rollups, err := storage.GetRollupRange(url, start, start.Add(time.Hour), time.Minute)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 2)
+	assert.Equal(t, int64(1), rollups[0].Count)
+	assert.Equal(t, int64(1), rollups[0].Failures)
+	assert.Equal(t, int64(100), rollups[0].Sum)
+	assert.Equal(t, int64(2), rollups[1].Count)
+	assert.InEpsilon(t, 500, rollups[1].Sum, 0.01)
+	assert.InEpsilon(t, 200, rollups[1].Min, 0.01)
+	assert.InEpsilon(t, 300, rollups[1].Max, 0.01)
+
+	rollups, err = storage.GetRollupRange(url, start, start.Add(time.Hour), time.Hour)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 1)
+	assert.Equal(t, int64(3), rollups[0].Count)
+
+	p, err := storage.GetLatencyPercentiles(url, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), p.Count)
+}
+
+func TestCompact(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 2000; i++ {
+		err := bolt.PutResult("https://ya.ru", &Result{Time: start.Add(time.Duration(i) * time.Second), Latency: int64(i)})
+		assert.Nil(t, err)
+	}
+	n, err := bolt.Prune(Retention{MaxPoints: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 1990, n)
+
+	before, err := os.Stat(dbTestName)
+	assert.Nil(t, err)
+	err = bolt.Compact()
+	assert.Nil(t, err)
+	after, err := os.Stat(dbTestName)
+	assert.Nil(t, err)
+	assert.True(t, after.Size() < before.Size())
+
+	// после сжатия данные доступны
+	lat, err := bolt.GetLastLatency("https://ya.ru")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1999), lat)
+	avg, err := bolt.GetAvgLatency("https://ya.ru")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(999), avg)
+	points, err := bolt.GetLatencyRange("https://ya.ru", time.Time{}, time.Now(), 0)
+	assert.Nil(t, err)
+	assert.Len(t, points, 10)
+}
+
+func TestCompactConcurrentWrites(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 1000; i++ {
+		err := bolt.PutResult("https://ya.ru", &Result{Time: start.Add(time.Duration(i) * time.Second), Latency: 1})
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, bolt.PutSilence(&Silence{Start: start, End: start.Add(time.Hour)}))
+
+	// запись во время сжатия не блокируется и не теряется
+	stop := make(chan struct{})
+	done := make(chan int)
+	var during int // отключений, созданных во время сжатия
+	go func() {
+		n := 0
+		for ; ; n++ {
+			select {
+			case <-stop:
+				done <- n
+				return
+			default:
+			}
+			err := bolt.PutResult("https://ya.ru", &Result{Time: start.Add(time.Duration(1000+n) * time.Second), Latency: 1})
+			assert.Nil(t, err)
+
+			bolt.journalMu.Lock()
+			compacting := bolt.compacting
+			bolt.journalMu.Unlock()
+			err = bolt.PutSilence(&Silence{Start: start, End: start.Add(time.Hour)})
+			assert.Nil(t, err)
+			if compacting {
+				during++
+			}
+		}
+	}()
+	err := bolt.Compact()
+	assert.Nil(t, err)
+	close(stop)
+	n := <-done
+	assert.NotZero(t, during)
+
+	points, err := bolt.GetLatencyRange("https://ya.ru", time.Time{}, time.Now(), 0)
+	assert.Nil(t, err)
+	assert.Len(t, points, 1000+n)
+
+	// id отключений, созданных до, во время и после сжатия, не повторяются
+	assert.Nil(t, bolt.PutSilence(&Silence{Start: start, End: start.Add(time.Hour)}))
+	silences, err := bolt.ListSilences()
+	assert.Nil(t, err)
+	assert.Len(t, silences, 1+n+1)
+	for i := range silences {
+		assert.Equal(t, uint64(i+1), silences[i].ID)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	for i := 0; i < 5; i++ {
+		err := bolt.PutLatency("https://ya.ru", int64(i))
+		assert.Nil(t, err)
+	}
+	bolt.StartJanitor(Retention{MaxPoints: 1, Interval: 10 * time.Millisecond, CompactInterval: 15 * time.Millisecond})
+	time.Sleep(100 * time.Millisecond)
+
+	points, err := bolt.GetLatencyRange("https://ya.ru", time.Time{}, time.Now(), 0)
+	assert.Nil(t, err)
+	assert.Len(t, points, 1)
+}
+
+func TestGetRollupRange(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	_, err := bolt.GetRollupRange("https://ya.ru", time.Time{}, time.Now(), time.Hour) // not exists
+	assert.Error(t, err)
+
+	// два дня значений каждые 10 минут, каждое шестое - отказ
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2*24*6; i++ {
+		lat := int64(i%6) * 100
+		if i%6 == 5 {
+			lat = -1
+		}
+		err := bolt.PutResult("https://ya.ru", &Result{Time: start.Add(time.Duration(i) * 10 * time.Minute), Latency: lat})
+		assert.Nil(t, err)
+	}
+	end := start.Add(48 * time.Hour)
+
+	// шаг меньше минуты - исходные значения
+	rollups, err := bolt.GetRollupRange("https://ya.ru", start, start.Add(time.Hour), time.Second)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 6)
+	assert.Equal(t, int64(1), rollups[5].Failures)
+
+	// шаг 15 минут - минутные агрегаты
+	rollups, err = bolt.GetRollupRange("https://ya.ru", start, start.Add(time.Hour), 15*time.Minute)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 6)
+
+	// шаг 3 часа - часовые агрегаты
+	rollups, err = bolt.GetRollupRange("https://ya.ru", start, end, 3*time.Hour)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 48)
+	r := rollups[0]
+	assert.True(t, r.Time.Equal(start))
+	assert.Equal(t, int64(5), r.Count)
+	assert.Equal(t, int64(1), r.Failures)
+	assert.Equal(t, int64(0), r.Min)
+	assert.Equal(t, int64(400), r.Max)
+	assert.Equal(t, int64(200), r.Avg())
+
+	// шаг неделя - дневные агрегаты
+	rollups, err = bolt.GetRollupRange("https://ya.ru", time.Time{}, end, 7*24*time.Hour)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 2)
+	assert.Equal(t, int64(24*5), rollups[1].Count)
+	assert.Equal(t, int64(24), rollups[1].Failures)
+
+	// интервал, начинающийся внутри часа, включает этот час
+	rollups, err = bolt.GetRollupRange("https://ya.ru", start.Add(90*time.Minute), start.Add(3*time.Hour), time.Hour)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 2)
+
+	// устаревшие агрегаты удаляются по уровням
+	_, err = bolt.Prune(Retention{RollupMaxAge: map[time.Duration]time.Duration{
+		TierMinute: time.Hour,
+		TierHour:   time.Since(start) - 24*time.Hour,
+	}})
+	assert.Nil(t, err)
+	rollups, err = bolt.GetRollupRange("https://ya.ru", start, end, time.Minute)
+	assert.Nil(t, err)
+	assert.Empty(t, rollups)
+	rollups, err = bolt.GetRollupRange("https://ya.ru", start, end, time.Hour)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 24)
+	rollups, err = bolt.GetRollupRange("https://ya.ru", start, end, TierDay)
+	assert.Nil(t, err)
+	assert.Len(t, rollups, 2)
+}
+
+func TestGetAvailability(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	_, err := bolt.GetAvailability("https://ya.ru", time.Time{}, time.Now()) // not exists
+	assert.Error(t, err)
+
+	// проверки каждую минуту, ресурс недоступен с 3-й по 5-ю минуту
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		lat := int64(100)
+		if i >= 3 && i < 6 {
+			lat = -1
+		}
+		err := bolt.PutResult("https://ya.ru", &Result{Time: start.Add(time.Duration(i) * time.Minute), Latency: lat})
+		assert.Nil(t, err)
+	}
+
+	a, err := bolt.GetAvailability("https://ya.ru", start, start.Add(10*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), a.Total)
+	assert.Equal(t, int64(3), a.Failures)
+	assert.InDelta(t, 70, a.Uptime, 0.001)
+	assert.Equal(t, 10*time.Minute, a.Observed)
+	assert.Equal(t, 3*time.Minute, a.Downtime)
+	assert.InDelta(t, 70, a.TimeUptime, 0.001)
+
+	// интервал начинается во время недоступности
+	a, err = bolt.GetAvailability("https://ya.ru", start.Add(4*time.Minute+30*time.Second), start.Add(8*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), a.Total)
+	assert.Equal(t, int64(1), a.Failures)
+	assert.Equal(t, 210*time.Second, a.Observed)
+	assert.Equal(t, 90*time.Second, a.Downtime)
+
+	// нет данных за интервал
+	_, err = bolt.GetAvailability("https://ya.ru", time.Time{}, start)
+	assert.Error(t, err)
+
+	urls, err := bolt.GetURLs()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"https://ya.ru"}, urls)
+}
+
+func TestListIncidents(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	incidents := []Incident{
+		{URL: "https://ya.ru", Start: start, End: start.Add(time.Hour), Reason: ReasonDNS},
+		{URL: "https://ya.ru", Start: start.Add(5 * time.Hour), Reason: ReasonTimeout},
+		{URL: "https://google.com", Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour), Reason: ReasonTLS},
+	}
+	for i := range incidents {
+		err := bolt.PutIncident(&incidents[i])
+		assert.Nil(t, err)
+	}
+
+	list, err := bolt.ListIncidents("https://ya.ru", time.Time{}, start.Add(24*time.Hour))
+	assert.Nil(t, err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, ReasonDNS, list[0].Reason)
+		assert.Equal(t, time.Hour, list[0].Duration(time.Now()))
+		assert.True(t, list[1].IsOpen())
+	}
+
+	// все url, пересекающиеся с интервалом
+	list, err = bolt.ListIncidents("", start.Add(30*time.Minute), start.Add(4*time.Hour))
+	assert.Nil(t, err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, "https://ya.ru", list[0].URL)
+		assert.Equal(t, "https://google.com", list[1].URL)
+	}
+
+	// закрытие открытого инцидента
+	incidents[1].End = start.Add(6 * time.Hour)
+	err = bolt.PutIncident(&incidents[1])
+	assert.Nil(t, err)
+	list, err = bolt.ListIncidents("https://ya.ru", start.Add(4*time.Hour), start.Add(24*time.Hour))
+	assert.Nil(t, err)
+	if assert.Len(t, list, 1) {
+		assert.False(t, list[0].IsOpen())
+		assert.Equal(t, time.Hour, list[0].Duration(time.Now()))
+	}
+
+	list, err = bolt.ListIncidents("https://notexist.eu", time.Time{}, time.Now())
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+}
+
+func TestSilences(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	err := bolt.PutSilence(&Silence{URL: "https://ya.ru", Start: start, End: start})
+	assert.Error(t, err, "empty interval")
+
+	s := &Silence{URL: "https://*.ru", Start: start, End: start.Add(time.Hour), Creator: "admin", Comment: "deploy"}
+	assert.Nil(t, bolt.PutSilence(s))
+	assert.Equal(t, uint64(1), s.ID)
+
+	// еженедельное обслуживание по средам с 02:00 до 03:00
+	m := &Maintenance{URL: "https://google.com", Start: start.Add(2 * time.Hour), Duration: time.Hour, Every: 7 * 24 * time.Hour}
+	assert.Nil(t, bolt.PutMaintenance(m))
+	assert.Error(t, bolt.PutMaintenance(&Maintenance{Duration: time.Hour, Every: time.Minute}))
+
+	silences, err := bolt.ListSilences()
+	assert.Nil(t, err)
+	assert.Equal(t, []Silence{*s}, silences)
+
+	check := func(url string, ts time.Time, silenced, maintenance bool) {
+		ok, err := bolt.IsSilenced(url, ts)
+		assert.Nil(t, err)
+		assert.Equal(t, silenced, ok, "IsSilenced %s %v", url, ts)
+		ok, err = bolt.InMaintenance(url, ts)
+		assert.Nil(t, err)
+		assert.Equal(t, maintenance, ok, "InMaintenance %s %v", url, ts)
+	}
+	check("https://ya.ru", start.Add(30*time.Minute), true, false)
+	check("https://ya.ru", start.Add(time.Hour), false, false)
+	check("https://google.com", start.Add(30*time.Minute), false, false)
+	check("https://google.com", start.Add(2*time.Hour), true, true)
+	check("https://google.com", start.Add(7*24*time.Hour+150*time.Minute), true, true)
+	check("https://google.com", start.Add(7*24*time.Hour+3*time.Hour), false, false)
+
+	assert.Nil(t, bolt.DeleteSilence(s.ID))
+	assert.Error(t, bolt.DeleteSilence(s.ID))
+	check("https://ya.ru", start.Add(30*time.Minute), false, false)
+
+	assert.Nil(t, bolt.DeleteMaintenance(m.ID))
+	windows, err := bolt.ListMaintenance()
+	assert.Nil(t, err)
+	assert.Empty(t, windows)
+}
+
+func TestAvailabilityMaintenance(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	// проверки каждую минуту, ресурс недоступен с 3-й по 5-ю минуту во время обслуживания
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		r := &Result{Time: start.Add(time.Duration(i) * time.Minute), Latency: 100}
+		if i >= 3 && i < 6 {
+			r.Latency, r.Maintenance = -1, true
+		}
+		assert.Nil(t, bolt.PutResult("https://ya.ru", r))
+	}
+
+	a, err := bolt.GetAvailability("https://ya.ru", start, start.Add(10*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), a.Total)
+	assert.Equal(t, int64(0), a.Failures)
+	assert.Equal(t, int64(3), a.Excluded)
+	assert.Equal(t, 7*time.Minute, a.Observed)
+	assert.InDelta(t, 100, a.TimeUptime, 0.001)
+
+	// интервал полностью во время обслуживания
+	a, err = bolt.GetAvailability("https://ya.ru", start.Add(3*time.Minute+30*time.Second), start.Add(5*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), a.Total)
+	assert.Equal(t, time.Duration(0), a.Observed)
+	assert.InDelta(t, 100, a.TimeUptime, 0.001)
+
+	urls, err := bolt.GetURLs()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"https://ya.ru"}, urls)
+}
+
+func TestTargets(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	assert.Nil(t, bolt.PutTarget("https://ya.ru", []byte(`{"URL":"https://ya.ru"}`)))
+	assert.Nil(t, bolt.PutTarget("https://google.com", []byte(`{"URL":"https://google.com"}`)))
+	assert.Nil(t, bolt.PutTarget("https://ya.ru", []byte(`{"URL":"https://ya.ru","Method":"GET"}`)))
+
+	configs, err := bolt.ListTargets()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{
+		[]byte(`{"URL":"https://google.com"}`),
+		[]byte(`{"URL":"https://ya.ru","Method":"GET"}`),
+	}, configs)
+
+	assert.Nil(t, bolt.DeleteTarget("https://google.com"))
+	assert.Error(t, bolt.DeleteTarget("https://google.com"))
+	configs, err = bolt.ListTargets()
+	assert.Nil(t, err)
+	assert.Len(t, configs, 1)
+
+	urls, err := bolt.GetURLs()
+	assert.Nil(t, err)
+	assert.Empty(t, urls)
+}
+
+func TestCertificates(t *testing.T) {
+	bolt := NewBoltStorage(dbTestName)
+	defer cleanup(bolt, t)
+
+	_, err := bolt.GetCertificate("https://ya.ru")
+	assert.Error(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	c := &CertInfo{
+		Time: now,
+		Chain: []Certificate{
+			{Subject: "CN=ya.ru", Issuer: "CN=CA", DNSNames: []string{"ya.ru"}, NotAfter: now.Add(48 * time.Hour)},
+			{Subject: "CN=CA", Issuer: "CN=Root", NotAfter: now.Add(24 * time.Hour)},
+		},
+		Warnings: []string{"expires soon"},
+	}
+	assert.Nil(t, bolt.PutCertificate("https://ya.ru", c))
+	got, err := bolt.GetCertificate("https://ya.ru")
+	assert.Nil(t, err)
+	assert.Equal(t, c, got)
+	assert.Equal(t, now.Add(24*time.Hour), got.Expires())
+
+	urls, err := bolt.GetURLs()
+	assert.Nil(t, err)
+	assert.Empty(t, urls)
+}
+
 func cleanup(bolt *BoltStorage, t *testing.T) {
 	err := bolt.Close()
 	assert.Nil(t, err, "Cant close db")
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// CertInfo - цепочка сертификатов, полученная при tls подключении к ресурсу.
+type CertInfo struct {
+	Time     time.Time     // время проверки
+	Chain    []Certificate // начиная с сертификата ресурса
+	Error    string        `json:",omitempty"` // ошибка проверки цепочки или имени ресурса
+	Warnings []string      `json:",omitempty"` // например, скорое истечение срока действия
+}
+
+// Certificate - основные поля x509 сертификата.
+type Certificate struct {
+	Subject            string
+	Issuer             string
+	DNSNames           []string `json:",omitempty"`
+	NotBefore          time.Time
+	NotAfter           time.Time
+	SignatureAlgorithm string
+}
+
+// Expires возвращает время окончания действия цепочки - минимальное NotAfter.
+func (c *CertInfo) Expires() time.Time {
+	var t time.Time
+	for _, cert := range c.Chain {
+		if t.IsZero() || cert.NotAfter.Before(t) {
+			t = cert.NotAfter
+		}
+	}
+	return t
+}
+
+// PutCertificate сохраняет сертификаты ресурса url, заменяя предыдущие.
+func (b *BoltStorage) PutCertificate(url string, c *CertInfo) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return b.update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(certificatesBucketName)).Put([]byte(url), data)
+	})
+}
+
+// GetCertificate возвращает последние сохраненные сертификаты ресурса url.
+func (b *BoltStorage) GetCertificate(url string) (*CertInfo, error) {
+	c := new(CertInfo)
+	err := b.view(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(certificatesBucketName)).Get([]byte(url))
+		if data == nil {
+			return errors.New(url + " has no certificate")
+		}
+		return json.Unmarshal(data, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
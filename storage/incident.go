@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Incident - период недоступности ресурса.
+type Incident struct {
+	URL    string    `json:"-"`
+	Start  time.Time `json:"-"` // время первой неудачной проверки
+	End    time.Time // время первой успешной проверки после восстановления, нулевое для открытого инцидента
+	Reason string    // причина недоступности при первой неудачной проверке
+	Error  string    `json:",omitempty"`
+}
+
+// IsOpen возвращает true, если ресурс еще не восстановился.
+func (i *Incident) IsOpen() bool {
+	return i.End.IsZero()
+}
+
+// Duration возвращает длительность инцидента. Для открытого инцидента -
+// время от начала до now.
+func (i *Incident) Duration(now time.Time) time.Duration {
+	if i.IsOpen() {
+		return now.Sub(i.Start)
+	}
+	return i.End.Sub(i.Start)
+}
+
+// PutIncident сохраняет инцидент. Инцидент идентифицируется url и временем начала,
+// поэтому повторное сохранение обновляет его.
+func (b *BoltStorage) PutIncident(inc *Incident) error {
+	binc, err := json.Marshal(inc)
+	if err != nil {
+		return err
+	}
+	url, key := inc.URL, encodeInt(inc.Start.UnixNano())
+	return b.update(func(tx *bolt.Tx) error {
+		bkt, err := tx.Bucket([]byte(incidentsBucketName)).CreateBucketIfNotExists([]byte(url))
+		if err != nil {
+			return err
+		}
+		return bkt.Put(key, binc)
+	})
+}
+
+// ListIncidents возвращает инциденты url, пересекающиеся с интервалом [from, to),
+// в порядке возрастания времени начала. Если url пустой, то инциденты всех url.
+func (b *BoltStorage) ListIncidents(url string, from, to time.Time) ([]Incident, error) {
+	incidents := []Incident{}
+	err := b.view(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte(incidentsBucketName))
+		collect := func(name []byte) error {
+			bkt := root.Bucket(name)
+			if bkt == nil {
+				return nil
+			}
+			end := to.UnixNano()
+			c := bkt.Cursor()
+			for k, v := c.First(); k != nil && decodeInt(k) < end; k, v = c.Next() {
+				inc := Incident{URL: string(name), Start: time.Unix(0, decodeInt(k))}
+				if err := json.Unmarshal(v, &inc); err != nil {
+					return err
+				}
+				if inc.IsOpen() || !inc.End.Before(from) {
+					incidents = append(incidents, inc)
+				}
+			}
+			return nil
+		}
+
+		if url != "" {
+			return collect([]byte(url))
+		}
+		return root.ForEach(func(name, _ []byte) error {
+			return collect(name)
+		})
+	})
+	if url == "" {
+		sort.SliceStable(incidents, func(i, j int) bool {
+			return incidents[i].Start.Before(incidents[j].Start)
+		})
+	}
+	return incidents, err
+}
@@ -0,0 +1,321 @@
+package storage
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Retention - политика хранения значений времени отклика в BoltStorage.
+type Retention struct {
+	MaxAge    time.Duration // максимальный возраст значений, 0 - без ограничения
+	MaxPoints int           // максимальное число значений для url, 0 - без ограничения
+
+	// максимальный возраст агрегатов по уровням (TierMinute, TierHour, TierDay),
+	// если для уровня не задан, то агрегаты не удаляются
+	RollupMaxAge map[time.Duration]time.Duration
+
+	Interval        time.Duration // период очистки
+	CompactInterval time.Duration // период сжатия файла базы, 0 - не сжимать
+}
+
+// pruneBatch - максимальное число ключей, удаляемых из бакета за одну транзакцию.
+const pruneBatch = 10000
+
+// StartJanitor запускает фоновую очистку базы согласно политике r.
+// Очистка останавливается при закрытии хранилища.
+func (b *BoltStorage) StartJanitor(r Retention) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopJanitor != nil {
+		return // уже запущена
+	}
+	stop := make(chan struct{})
+	b.stopJanitor = stop
+
+	b.janitorWG.Add(1)
+	go func() {
+		defer b.janitorWG.Done()
+		log.Printf("[INFO] Start janitor: %+v", r)
+
+		prune := time.NewTicker(r.Interval)
+		defer prune.Stop()
+		var compact <-chan time.Time
+		if r.CompactInterval > 0 {
+			t := time.NewTicker(r.CompactInterval)
+			defer t.Stop()
+			compact = t.C
+		}
+
+		for {
+			select {
+			case <-prune.C:
+				n, err := b.Prune(r)
+				if err != nil {
+					log.Println("[ERROR] Can't prune db", err)
+					continue
+				}
+				log.Printf("[INFO] Pruned %d points", n)
+			case <-compact:
+				if err := b.Compact(); err != nil {
+					log.Println("[ERROR] Can't compact db", err)
+				}
+			case <-stop:
+				log.Println("[INFO] Janitor was stoped")
+				return
+			}
+		}
+	}()
+}
+
+// Prune удаляет значения времени отклика, не удовлетворяющие политике r,
+// вместе с подробными результатами проверок и отметками об обслуживании,
+// и устаревшие агрегаты.
+// Возвращает число удаленных исходных значений.
+func (b *BoltStorage) Prune(r Retention) (int, error) {
+	urls, err := b.GetURLs()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var cutoff int64
+	if r.MaxAge > 0 {
+		cutoff = now.Add(-r.MaxAge).UnixNano()
+	}
+
+	total := 0
+	for _, url := range urls {
+		// удаляем порциями, чтобы не держать долго блокировку на запись;
+		// ключи выбираются заранее, чтобы запись не зависела от вызывающего
+		// при повторе во время сжатия (см. update)
+		for {
+			var keys [][]byte
+			err := b.view(func(tx *bolt.Tx) error {
+				keys = pruneKeys(tx, url, cutoff, r.MaxPoints)
+				return nil
+			})
+			if err != nil {
+				return total, err
+			}
+			if len(keys) > 0 {
+				err = b.update(func(tx *bolt.Tx) error {
+					return deleteKeys(tx, url, keys)
+				})
+				if err != nil {
+					return total, err
+				}
+			}
+			total += len(keys)
+			if len(keys) < pruneBatch {
+				break
+			}
+		}
+
+		err := b.update(func(tx *bolt.Tx) error {
+			return pruneRollups(tx, url, r.RollupMaxAge, now)
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// pruneRollups удаляет агрегаты url, период которых к моменту now закончился
+// раньше, чем допускает возраст для уровня.
+func pruneRollups(tx *bolt.Tx, url string, maxAge map[time.Duration]time.Duration, now time.Time) error {
+	for tier, age := range maxAge {
+		bkt := rollupBucket(tx, url, tier)
+		if bkt == nil || age <= 0 {
+			continue
+		}
+		cutoff := now.Add(-age).UnixNano()
+		if err := deleteBefore(bkt, cutoff-int64(tier)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneKeys возвращает не более pruneBatch ключей значений url, которые
+// старше cutoff или выходят за ограничение maxPoints.
+func pruneKeys(tx *bolt.Tx, url string, cutoff int64, maxPoints int) [][]byte {
+	bkt := tx.Bucket([]byte(url))
+	if bkt == nil {
+		return nil
+	}
+
+	extra := 0
+	if maxPoints > 0 {
+		if n := bkt.Stats().KeyN; n > maxPoints {
+			extra = n - maxPoints
+		}
+	}
+
+	var keys [][]byte
+	c := bkt.Cursor()
+	for k, _ := c.First(); k != nil && len(keys) < pruneBatch; k, _ = c.Next() {
+		if len(keys) >= extra && decodeInt(k) >= cutoff {
+			break
+		}
+		keys = append(keys, append([]byte(nil), k...)) // ключ действителен только в транзакции
+	}
+	return keys
+}
+
+// deleteKeys удаляет значения url с ключами keys вместе с подробными
+// результатами проверок и отметками об обслуживании.
+func deleteKeys(tx *bolt.Tx, url string, keys [][]byte) error {
+	bkt := tx.Bucket([]byte(url))
+	if bkt == nil {
+		return nil
+	}
+	results := tx.Bucket([]byte(resultsBucketName)).Bucket([]byte(url))
+	maintenance := tx.Bucket([]byte(maintenanceProbesBucketName)).Bucket([]byte(url))
+	for _, k := range keys {
+		if err := bkt.Delete(k); err != nil {
+			return err
+		}
+		for _, b := range []*bolt.Bucket{results, maintenance} {
+			if b == nil {
+				continue
+			}
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteBefore удаляет из бакета ключи-timestamp меньше ts.
+func deleteBefore(bkt *bolt.Bucket, ts int64) error {
+	var keys [][]byte
+	c := bkt.Cursor()
+	for k, _ := c.First(); k != nil && decodeInt(k) < ts; k, _ = c.Next() {
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		if err := bkt.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compact переписывает файл базы, освобождая место, оставшееся после
+// удаления данных. Данные копируются из снимка базы, остальные операции
+// при этом не блокируются. Изменения, сделанные за время копирования,
+// затем повторяются в новом файле, и только на время повтора и подмены
+// файла операции блокируются. При ошибке хранилище продолжает работать
+// с прежним файлом.
+func (b *BoltStorage) Compact() error {
+	tmpPath := b.path + ".compact"
+
+	b.mu.RLock()
+	b.journalMu.Lock()
+	if b.compacting {
+		b.journalMu.Unlock()
+		b.mu.RUnlock()
+		return errors.New("compaction is already running")
+	}
+	// снимок и начало журнала под одной блокировкой, чтобы каждое изменение
+	// попало либо в снимок, либо в журнал
+	src, err := b.db.Begin(false)
+	if err != nil {
+		b.journalMu.Unlock()
+		b.mu.RUnlock()
+		return err
+	}
+	b.compacting = true
+	b.journalMu.Unlock()
+
+	os.Remove(tmpPath) // файл мог остаться от прерванного сжатия
+	dst, err := openBolt(tmpPath)
+	if err == nil {
+		err = copyDB(dst, src)
+	}
+	src.Rollback()
+	b.mu.RUnlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.journalMu.Lock()
+	journal := b.journal
+	b.compacting, b.journal = false, nil
+	b.journalMu.Unlock()
+	if err == nil {
+		err = dst.Update(func(tx *bolt.Tx) error {
+			for _, fn := range journal {
+				if err := fn(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		// открытый dst продолжает работать с файлом после переименования
+		err = os.Rename(tmpPath, b.path)
+	}
+	if err != nil {
+		if dst != nil {
+			dst.Close()
+			os.Remove(tmpPath)
+		}
+		return err
+	}
+
+	old := b.db
+	b.db = dst
+	if err := old.Close(); err != nil {
+		log.Println("[WARN] Can't close db replaced by compaction", err)
+	}
+	return nil
+}
+
+// copyDB копирует все бакеты из транзакции src в dst, по транзакции
+// на каждый бакет верхнего уровня.
+func copyDB(dst *bolt.DB, src *bolt.Tx) error {
+	return src.ForEach(func(name []byte, sbkt *bolt.Bucket) error {
+		return dst.Update(func(dtx *bolt.Tx) error {
+			dbkt, err := dtx.CreateBucket(name)
+			if err != nil {
+				return err
+			}
+			return copyBucket(dbkt, sbkt)
+		})
+	})
+}
+
+func copyBucket(dst, src *bolt.Bucket) error {
+	// ключи копируются по возрастанию, поэтому страницы можно заполнять полностью
+	dst.FillPercent = 1
+	// последовательность используется для id (silence, maintenance)
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+		child, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(child, src.Bucket(k))
+	})
+}
+
+func isServiceBucket(name string) bool {
+	for _, s := range serviceBuckets {
+		if name == s {
+			return true
+		}
+	}
+	return false
+}
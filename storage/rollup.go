@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Уровни агрегации времени отклика
+const (
+	TierMinute = time.Minute
+	TierHour   = time.Hour
+	TierDay    = 24 * time.Hour
+)
+
+// tiers - уровни агрегации от мелкого к крупному.
+var tiers = []time.Duration{TierMinute, TierHour, TierDay}
+
+// Rollup - агрегат времени отклика url за период.
+type Rollup struct {
+	Time     time.Time `json:"-"` // начало периода
+	Count    int64     // число проверок, когда ресурс был доступен
+	Failures int64     // число проверок, когда ресурс был недоступен
+	Sum      int64     // сумма времени отклика (нс)
+	Min      int64
+	Max      int64
+	Sketch   *Sketch
+}
+
+// Add добавляет в агрегат время отклика lat, -1 если ресурс был недоступен.
+func (r *Rollup) Add(lat int64) {
+	if lat < 0 {
+		r.Failures++
+		return
+	}
+	if r.Count == 0 || lat < r.Min {
+		r.Min = lat
+	}
+	if lat > r.Max {
+		r.Max = lat
+	}
+	r.Count++
+	r.Sum += lat
+	if r.Sketch == nil {
+		r.Sketch = NewSketch()
+	}
+	r.Sketch.Add(lat)
+}
+
+// Merge добавляет к агрегату значения из other.
+func (r *Rollup) Merge(other *Rollup) {
+	if other.Count > 0 {
+		if r.Count == 0 || other.Min < r.Min {
+			r.Min = other.Min
+		}
+		if other.Max > r.Max {
+			r.Max = other.Max
+		}
+		if r.Sketch == nil {
+			r.Sketch = NewSketch()
+		}
+		r.Sketch.Merge(other.Sketch)
+	}
+	r.Count += other.Count
+	r.Failures += other.Failures
+	r.Sum += other.Sum
+}
+
+// Avg возвращает среднее время отклика, 0 если ресурс не был доступен.
+func (r *Rollup) Avg() int64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return r.Sum / r.Count
+}
+
+// tierName возвращает имя бакета уровня агрегации.
+func tierName(tier time.Duration) []byte {
+	switch tier {
+	case TierMinute:
+		return []byte("1m")
+	case TierHour:
+		return []byte("1h")
+	}
+	return []byte("1d")
+}
+
+// tierFor возвращает самый крупный уровень агрегации с периодом не больше resolution.
+// Если resolution меньше минуты, возвращается 0 - исходные значения.
+func tierFor(resolution time.Duration) time.Duration {
+	var tier time.Duration
+	for _, t := range tiers {
+		if t <= resolution {
+			tier = t
+		}
+	}
+	return tier
+}
+
+// maxTierPoints - число периодов в окне, при превышении которого для расчета
+// квантилей берется более крупный уровень агрегации.
+const maxTierPoints = 24 * 60
+
+// tierForWindow возвращает самый мелкий уровень агрегации, на котором окно
+// window содержит не более maxTierPoints периодов. Для окна за все время
+// используется самый крупный уровень.
+func tierForWindow(window time.Duration) time.Duration {
+	if window <= 0 {
+		return tiers[len(tiers)-1]
+	}
+	for _, t := range tiers {
+		if window/t <= maxTierPoints {
+			return t
+		}
+	}
+	return tiers[len(tiers)-1]
+}
+
+// putRollups добавляет время отклика во все уровни агрегации.
+func putRollups(tx *bolt.Tx, url string, ts int64, lat int64) error {
+	r := new(Rollup)
+	r.Add(lat)
+	return mergeRollups(tx, url, ts, r)
+}
+
+// mergeRollups добавляет агрегат r за период, в который попадает ts,
+// во все уровни агрегации. Период r не должен быть больше минуты.
+func mergeRollups(tx *bolt.Tx, url string, ts int64, r *Rollup) error {
+	root := tx.Bucket([]byte(rollupsBucketName))
+	for _, tier := range tiers {
+		tierBkt, err := root.CreateBucketIfNotExists(tierName(tier))
+		if err != nil {
+			return err
+		}
+		bkt, err := tierBkt.CreateBucketIfNotExists([]byte(url))
+		if err != nil {
+			return err
+		}
+
+		key := encodeInt(ts - ts%int64(tier))
+		item := new(Rollup)
+		if bitem := bkt.Get(key); bitem != nil {
+			if err := json.Unmarshal(bitem, item); err != nil {
+				return err
+			}
+		}
+		item.Merge(r)
+
+		bitem, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if err := bkt.Put(key, bitem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSketches переносит поминутные гистограммы из бакета "Sketches",
+// в котором раньше хранились данные для квантилей, в агрегаты и удаляет бакет.
+// Агрегат за минуту строится по исходным значениям, а если они уже удалены
+// политикой хранения, то по гистограмме: минимум, максимум и сумма при этом
+// оцениваются с погрешностью гистограммы, а отказы неизвестны.
+func migrateSketches(tx *bolt.Tx) error {
+	legacy := tx.Bucket([]byte(legacySketchesBucketName))
+	if legacy == nil {
+		return nil
+	}
+	err := legacy.ForEach(func(url, v []byte) error {
+		if v != nil {
+			return nil
+		}
+		return migrateURLSketches(tx, string(url), legacy.Bucket(url))
+	})
+	if err != nil {
+		return err
+	}
+	return tx.DeleteBucket([]byte(legacySketchesBucketName))
+}
+
+// migrateURLSketches строит агрегаты url за время, когда для него велись
+// гистограммы sketches.
+func migrateURLSketches(tx *bolt.Tx, url string, sketches *bolt.Bucket) error {
+	first, _ := sketches.Cursor().First()
+	if first == nil {
+		return nil
+	}
+
+	// исходные значения, начиная с первой гистограммы, включая отказы
+	minutes := make(map[int64]*Rollup)
+	if raw := tx.Bucket([]byte(url)); raw != nil {
+		c := raw.Cursor()
+		for k, v := c.Seek(first); k != nil; k, v = c.Next() {
+			ts := decodeInt(k)
+			ts -= ts % int64(TierMinute)
+			if minutes[ts] == nil {
+				minutes[ts] = new(Rollup)
+			}
+			minutes[ts].Add(decodeInt(v))
+		}
+	}
+	err := sketches.ForEach(func(k, v []byte) error {
+		if ts := decodeInt(k); minutes[ts] == nil {
+			sk := NewSketch()
+			if err := json.Unmarshal(v, sk); err != nil {
+				return err
+			}
+			minutes[ts] = sketchRollup(sk)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for ts, r := range minutes {
+		if err := mergeRollups(tx, url, ts, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sketchRollup возвращает агрегат, оцененный по гистограмме sk.
+func sketchRollup(sk *Sketch) *Rollup {
+	r := &Rollup{Count: sk.Count, Sketch: sk}
+	if sk.Count == 0 {
+		return r
+	}
+	if sk.Zero == 0 {
+		r.Min = -1
+	}
+	for i, n := range sk.Bins {
+		v := sketchValue(i)
+		if r.Min < 0 || v < r.Min {
+			r.Min = v
+		}
+		if v > r.Max {
+			r.Max = v
+		}
+		r.Sum += v * n
+	}
+	return r
+}
+
+// rollupBucket возвращает бакет агрегатов url на уровне tier или nil.
+func rollupBucket(tx *bolt.Tx, url string, tier time.Duration) *bolt.Bucket {
+	tierBkt := tx.Bucket([]byte(rollupsBucketName)).Bucket(tierName(tier))
+	if tierBkt == nil {
+		return nil
+	}
+	return tierBkt.Bucket([]byte(url))
+}
+
+// eachRollup вызывает fn для агрегатов url уровня tier, периоды которых
+// пересекаются с интервалом [from, to).
+func eachRollup(tx *bolt.Tx, url string, tier time.Duration, from, to time.Time, fn func(r *Rollup) error) error {
+	bkt := rollupBucket(tx, url, tier)
+	if bkt == nil {
+		return errors.New(url + " not exists")
+	}
+
+	var start time.Time
+	if !from.IsZero() {
+		ts := from.UnixNano()
+		start = time.Unix(0, ts-ts%int64(tier))
+	}
+	end := to.UnixNano()
+	c := bkt.Cursor()
+	for k, v := seek(c, start); k != nil && decodeInt(k) < end; k, v = c.Next() {
+		r := new(Rollup)
+		if err := json.Unmarshal(v, r); err != nil {
+			return err
+		}
+		r.Time = time.Unix(0, decodeInt(k))
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRollupRange возвращает агрегаты времени отклика url за интервал [from, to)
+// с шагом не больше resolution. Используется самый крупный подходящий уровень
+// агрегации (минута, час, день). Если resolution меньше минуты, то каждый
+// агрегат соответствует одному исходному значению.
+func (b *BoltStorage) GetRollupRange(url string, from, to time.Time, resolution time.Duration) ([]Rollup, error) {
+	tier := tierFor(resolution)
+	if tier == 0 {
+		points, err := b.GetLatencyRange(url, from, to, 0)
+		if err != nil {
+			return nil, err
+		}
+		rollups := make([]Rollup, len(points))
+		for i, p := range points {
+			rollups[i].Time = p.Time
+			rollups[i].Add(p.Latency)
+		}
+		return rollups, nil
+	}
+
+	rollups := []Rollup{}
+	err := b.view(func(tx *bolt.Tx) error {
+		return eachRollup(tx, url, tier, from, to, func(r *Rollup) error {
+			rollups = append(rollups, *r)
+			return nil
+		})
+	})
+	return rollups, err
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Silence - отключение оповещений для url на интервал [Start, End).
+type Silence struct {
+	ID      uint64 `json:"-"`
+	URL     string // шаблон url в формате path.Match, пустой - все url
+	Start   time.Time
+	End     time.Time
+	Creator string `json:",omitempty"`
+	Comment string `json:",omitempty"`
+}
+
+// Validate проверяет настройки отключения оповещений.
+func (s *Silence) Validate() error {
+	if _, err := path.Match(s.URL, ""); err != nil {
+		return fmt.Errorf("bad url pattern %q: %v", s.URL, err)
+	}
+	if !s.End.After(s.Start) {
+		return errors.New("silence end must be after start")
+	}
+	return nil
+}
+
+// Active возвращает true, если оповещения для url отключены в момент t.
+func (s *Silence) Active(url string, t time.Time) bool {
+	return matchURL(s.URL, url) && !t.Before(s.Start) && t.Before(s.End)
+}
+
+// Maintenance - окно обслуживания длительностью Duration, которое начинается
+// в Start и повторяется каждые Every до Until. Проверки во время обслуживания
+// отмечаются в Result.Maintenance и не учитываются при расчете доступности,
+// а оповещения не отправляются.
+type Maintenance struct {
+	ID       uint64        `json:"-"`
+	URL      string        // шаблон url в формате path.Match, пустой - все url
+	Start    time.Time     // начало первого окна
+	Duration time.Duration // длительность окна
+	Every    time.Duration `json:",omitempty"` // период повторения, 0 - не повторяется
+	Until    time.Time     // окончание повторений, нулевое - без ограничения
+	Creator  string        `json:",omitempty"`
+	Comment  string        `json:",omitempty"`
+}
+
+// Validate проверяет настройки окна обслуживания.
+func (m *Maintenance) Validate() error {
+	if _, err := path.Match(m.URL, ""); err != nil {
+		return fmt.Errorf("bad url pattern %q: %v", m.URL, err)
+	}
+	if m.Duration <= 0 {
+		return errors.New("maintenance duration must be positive")
+	}
+	if m.Every < 0 || (m.Every > 0 && m.Every < m.Duration) {
+		return errors.New("maintenance period must not be less than duration")
+	}
+	return nil
+}
+
+// Active возвращает true, если в момент t для url идет обслуживание.
+func (m *Maintenance) Active(url string, t time.Time) bool {
+	if !matchURL(m.URL, url) || t.Before(m.Start) {
+		return false
+	}
+	if !m.Until.IsZero() && !t.Before(m.Until) {
+		return false
+	}
+	d := t.Sub(m.Start)
+	if m.Every > 0 {
+		d %= m.Every
+	}
+	return d < m.Duration
+}
+
+func matchURL(pattern, url string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, url)
+	return ok
+}
+
+// PutSilence сохраняет отключение оповещений. Если ID не задан, то он
+// назначается и записывается в s, иначе сохраненное ранее отключение обновляется.
+func (b *BoltStorage) PutSilence(s *Silence) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	return b.putItem(silencesBucketName, &s.ID, s)
+}
+
+// DeleteSilence удаляет отключение оповещений.
+func (b *BoltStorage) DeleteSilence(id uint64) error {
+	return b.update(func(tx *bolt.Tx) error {
+		return deleteItem(tx.Bucket([]byte(silencesBucketName)), "silence", id)
+	})
+}
+
+// ListSilences возвращает отключения оповещений в порядке создания.
+func (b *BoltStorage) ListSilences() ([]Silence, error) {
+	silences := []Silence{}
+	err := b.view(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(silencesBucketName)).ForEach(func(k, v []byte) error {
+			s := Silence{ID: uint64(decodeInt(k))}
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			silences = append(silences, s)
+			return nil
+		})
+	})
+	return silences, err
+}
+
+// PutMaintenance сохраняет окно обслуживания. Если ID не задан, то он
+// назначается и записывается в m, иначе сохраненное ранее окно обновляется.
+func (b *BoltStorage) PutMaintenance(m *Maintenance) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	return b.putItem(maintenanceBucketName, &m.ID, m)
+}
+
+// DeleteMaintenance удаляет окно обслуживания.
+func (b *BoltStorage) DeleteMaintenance(id uint64) error {
+	return b.update(func(tx *bolt.Tx) error {
+		return deleteItem(tx.Bucket([]byte(maintenanceBucketName)), "maintenance", id)
+	})
+}
+
+// ListMaintenance возвращает окна обслуживания в порядке создания.
+func (b *BoltStorage) ListMaintenance() ([]Maintenance, error) {
+	windows := []Maintenance{}
+	err := b.view(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(maintenanceBucketName)).ForEach(func(k, v []byte) error {
+			m := Maintenance{ID: uint64(decodeInt(k))}
+			if err := json.Unmarshal(v, &m); err != nil {
+				return err
+			}
+			windows = append(windows, m)
+			return nil
+		})
+	})
+	return windows, err
+}
+
+// InMaintenance возвращает true, если в момент t для url идет обслуживание.
+func (b *BoltStorage) InMaintenance(url string, t time.Time) (bool, error) {
+	windows, err := b.ListMaintenance()
+	if err != nil {
+		return false, err
+	}
+	for i := range windows {
+		if windows[i].Active(url, t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsSilenced возвращает true, если оповещения для url в момент t отключены
+// или для url идет обслуживание.
+func (b *BoltStorage) IsSilenced(url string, t time.Time) (bool, error) {
+	silences, err := b.ListSilences()
+	if err != nil {
+		return false, err
+	}
+	for i := range silences {
+		if silences[i].Active(url, t) {
+			return true, nil
+		}
+	}
+	return b.InMaintenance(url, t)
+}
+
+// putItem сохраняет v в виде json в бакет name с ключом id, назначая новый id,
+// если он равен 0. Последовательность бакета продвигается до id и при записи,
+// чтобы она не отстала при повторе записи после сжатия файла (см. Compact).
+func (b *BoltStorage) putItem(name string, id *uint64, v interface{}) error {
+	if *id == 0 {
+		seq, err := b.nextSequence(name)
+		if err != nil {
+			return err
+		}
+		*id = seq
+	}
+	bv, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	key := *id
+	return b.update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte(name))
+		if key > bkt.Sequence() {
+			if err := bkt.SetSequence(key); err != nil {
+				return err
+			}
+		}
+		return bkt.Put(encodeInt(int64(key)), bv)
+	})
+}
+
+// nextSequence возвращает следующее значение последовательности бакета name.
+// Не попадает в журнал сжатия: в новом файле последовательность продвигается
+// при записи элемента с этим id.
+func (b *BoltStorage) nextSequence(name string) (uint64, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var seq uint64
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		seq, err = tx.Bucket([]byte(name)).NextSequence()
+		return err
+	})
+	return seq, err
+}
+
+func deleteItem(bkt *bolt.Bucket, kind string, id uint64) error {
+	key := encodeInt(int64(id))
+	if bkt.Get(key) == nil {
+		return errors.New(kind + " " + strconv.FormatUint(id, 10) + " not exists")
+	}
+	return bkt.Delete(key)
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"math"
+	"sort"
+)
+
+// sketchAccuracy - относительная погрешность оценки квантилей.
+const sketchAccuracy = 0.01
+
+var sketchGamma = (1 + sketchAccuracy) / (1 - sketchAccuracy)
+
+// Sketch - гистограмма времени отклика с логарифмическими корзинами
+// (по аналогии с HDR histogram), позволяющая оценивать квантили с
+// относительной погрешностью sketchAccuracy. Гистограммы объединяются
+// сложением счетчиков, поэтому их можно хранить по интервалам времени
+// и сливать при запросе за произвольное окно.
+type Sketch struct {
+	Count int64
+	Zero  int64           // число нулевых значений
+	Bins  map[int32]int64 // индекс корзины -> число значений
+}
+
+// Percentiles - оценка квантилей времени отклика (нс).
+type Percentiles struct {
+	Count int64
+	P50   int64
+	P90   int64
+	P95   int64
+	P99   int64
+}
+
+func NewSketch() *Sketch {
+	return &Sketch{Bins: make(map[int32]int64)}
+}
+
+// Add добавляет значение v >= 0 в гистограмму.
+func (s *Sketch) Add(v int64) {
+	s.Count++
+	if v <= 0 {
+		s.Zero++
+		return
+	}
+	if s.Bins == nil {
+		s.Bins = make(map[int32]int64)
+	}
+	s.Bins[sketchIndex(v)]++
+}
+
+// Merge добавляет к гистограмме значения из other.
+func (s *Sketch) Merge(other *Sketch) {
+	if s.Bins == nil {
+		s.Bins = make(map[int32]int64)
+	}
+	s.Count += other.Count
+	s.Zero += other.Zero
+	for i, n := range other.Bins {
+		s.Bins[i] += n
+	}
+}
+
+// Quantile возвращает оценку квантиля q (0 <= q <= 1).
+func (s *Sketch) Quantile(q float64) int64 {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := int64(q * float64(s.Count-1))
+	if rank < s.Zero {
+		return 0
+	}
+	seen := s.Zero
+
+	keys := make([]int, 0, len(s.Bins))
+	for i := range s.Bins {
+		keys = append(keys, int(i))
+	}
+	sort.Ints(keys)
+	for _, i := range keys {
+		seen += s.Bins[int32(i)]
+		if seen > rank {
+			return sketchValue(int32(i))
+		}
+	}
+	return sketchValue(int32(keys[len(keys)-1]))
+}
+
+// Percentiles возвращает p50, p90, p95 и p99.
+func (s *Sketch) Percentiles() *Percentiles {
+	return &Percentiles{
+		Count: s.Count,
+		P50:   s.Quantile(0.5),
+		P90:   s.Quantile(0.9),
+		P95:   s.Quantile(0.95),
+		P99:   s.Quantile(0.99),
+	}
+}
+
+func sketchIndex(v int64) int32 {
+	return int32(math.Ceil(math.Log(float64(v)) / math.Log(sketchGamma)))
+}
+
+// sketchValue возвращает значение, соответствующее середине корзины i.
+func sketchValue(i int32) int64 {
+	return int64(math.Round(2 * math.Pow(sketchGamma, float64(i)) / (sketchGamma + 1)))
+}
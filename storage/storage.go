@@ -1,14 +1,89 @@
 package storage
 
+import "time"
+
 type Storage interface {
 	PutLatency(url string, lat int64) error
+	PutResult(url string, r *Result) error
 	GetMaxLatency() (string, int64, error)
 	GetMinLatency() (string, int64, error)
 	GetLastLatency(url string) (int64, error)
+	GetLastResult(url string) (*Result, error)
 	GetAvgLatency(url string) (int64, error)
+	GetMaxLatencyRange(from, to time.Time) (string, int64, error)
+	GetMinLatencyRange(from, to time.Time) (string, int64, error)
+	GetAvgLatencyRange(url string, from, to time.Time) (int64, error)
+	GetLatencyPercentiles(url string, window time.Duration) (*Percentiles, error)
+	GetLatencyRange(url string, from, to time.Time, limit int) ([]Point, error)
+	GetRollupRange(url string, from, to time.Time, resolution time.Duration) ([]Rollup, error)
+	GetAvailability(url string, from, to time.Time) (*Availability, error)
+	GetURLs() ([]string, error)
+	PutIncident(inc *Incident) error
+	ListIncidents(url string, from, to time.Time) ([]Incident, error)
+	PutSilence(s *Silence) error
+	DeleteSilence(id uint64) error
+	ListSilences() ([]Silence, error)
+	PutMaintenance(m *Maintenance) error
+	DeleteMaintenance(id uint64) error
+	ListMaintenance() ([]Maintenance, error)
+	InMaintenance(url string, t time.Time) (bool, error)
+	IsSilenced(url string, t time.Time) (bool, error)
+	PutTarget(url string, config []byte) error
+	DeleteTarget(url string) error
+	ListTargets() ([][]byte, error)
+	PutCertificate(url string, c *CertInfo) error
+	GetCertificate(url string) (*CertInfo, error)
 	Close() error
 }
 
+// Result - результат одной проверки ресурса.
+type Result struct {
+	Time    time.Time `json:"-"` // время проверки, если не задано - текущее
+	Latency int64     // время отклика (нс), -1 если ресурс недоступен
+	Phases  *Phases   `json:",omitempty"` // время этапов запроса
+	Status  int       `json:",omitempty"` // http код ответа
+	Reason  string    `json:",omitempty"` // причина недоступности ресурса, одна из Reason*
+	Check   string    `json:",omitempty"` // проверка ответа, которая не прошла
+	Error   string    `json:",omitempty"` // описание ошибки
+
+	Maintenance bool    `json:",omitempty"` // проверка во время обслуживания, не учитывается в доступности
+	Flapping    bool    `json:",omitempty"` // ресурс нестабилен, часто меняет состояние
+	StateChange float64 `json:",omitempty"` // процент смены состояния за последние проверки
+
+	Attempts int  `json:",omitempty"` // число попыток проверки, 0 - до появления повторов
+	Retried  bool `json:",omitempty"` // ресурс доступен только после повторных попыток
+
+	// сертификаты https ресурса, сохраняются отдельно (PutCertificate)
+	Certificate *CertInfo `json:"-"`
+}
+
+// Point - время отклика (нс) в момент времени Time, -1 если ресурс был недоступен.
+type Point struct {
+	Time    time.Time
+	Latency int64
+}
+
+// Phases - время (нс) этапов http запроса.
+type Phases struct {
+	DNS      int64 // разрешение имени
+	Connect  int64 // установка tcp соединения
+	TLS      int64 // tls рукопожатие
+	TTFB     int64 // от отправки запроса до первого байта ответа
+	Transfer int64 // получение тела ответа
+}
+
+// Причины недоступности ресурса
+const (
+	ReasonDNS          = "dns"           // ошибка разрешения имени
+	ReasonConnect      = "connect"       // ошибка установки соединения
+	ReasonTLS          = "tls"           // ошибка tls рукопожатия или сертификата
+	ReasonTimeout      = "timeout"       // превышено время ожидания ответа
+	ReasonHTTPStatus   = "http_status"   // недопустимый код ответа
+	ReasonBodyMismatch = "body_mismatch" // тело ответа не прошло проверку
+	ReasonNotServing   = "not_serving"   // grpc сервис не в статусе SERVING
+	ReasonUnknown      = "unknown"       // прочие ошибки
+)
+
 func New(path string) Storage {
 	return NewBoltStorage(path)
 }
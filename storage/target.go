@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// PutTarget сохраняет настройки опроса ресурса url. Настройки хранятся
+// в виде json, формат которого определяется поллером (poller.Target).
+func (b *BoltStorage) PutTarget(url string, config []byte) error {
+	config = append([]byte(nil), config...)
+	return b.update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(targetsBucketName)).Put([]byte(url), config)
+	})
+}
+
+// DeleteTarget удаляет настройки опроса ресурса url.
+func (b *BoltStorage) DeleteTarget(url string) error {
+	return b.update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte(targetsBucketName))
+		if bkt.Get([]byte(url)) == nil {
+			return errors.New(url + " not exists")
+		}
+		return bkt.Delete([]byte(url))
+	})
+}
+
+// ListTargets возвращает сохраненные настройки опроса ресурсов в порядке url.
+func (b *BoltStorage) ListTargets() ([][]byte, error) {
+	configs := [][]byte{}
+	err := b.view(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(targetsBucketName)).ForEach(func(_, v []byte) error {
+			configs = append(configs, append([]byte(nil), v...))
+			return nil
+		})
+	})
+	return configs, err
+}